@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/bitx/bitx-go"
+)
+
+// errAborted is returned when the user doesn't confirm a command.
+var errAborted = errors.New("aborted")
+
+type command func(cl *cli, args []string) error
+
+var commands = map[string]command{
+	"ticker":    (*cli).ticker,
+	"orderbook": (*cli).orderBook,
+	"trades":    (*cli).trades,
+	"balance":   (*cli).balance,
+	"orders":    (*cli).orders,
+	"send":      (*cli).send,
+}
+
+var orderCommands = map[string]command{
+	"list": (*cli).listOrders,
+	"get":  (*cli).getOrder,
+	"post": (*cli).postOrder,
+	"stop": (*cli).stopOrder,
+}
+
+// run runs the command named by args[0].
+func (cl *cli) run(args []string) error {
+	switch cl.format {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("unknown format %q", cl.format)
+	}
+	return dispatch(cl, commands, "", args)
+}
+
+func dispatch(cl *cli, cmds map[string]command, prefix string,
+	args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%smissing command", prefix)
+	}
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		return fmt.Errorf("%sunknown command %q", prefix, args[0])
+	}
+	return cmd(cl, args[1:])
+}
+
+// flags returns a flag set for a command's options which reports errors
+// instead of exiting.
+func flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// wantArgs checks the number of arguments of a command.
+func wantArgs(args []string, n int, usage string) error {
+	if len(args) != n {
+		return fmt.Errorf("usage: bitx %s", usage)
+	}
+	return nil
+}
+
+// confirm asks the user to confirm an action, returning errAborted unless
+// they answer y or yes.
+func (cl *cli) confirm(format string, args ...interface{}) error {
+	fmt.Fprintf(cl.errOut, format+"? [y/N] ", args...)
+	line, err := cl.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(cl.errOut)
+		return errAborted
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+func (cl *cli) ticker(args []string) error {
+	var ts []bitx.Ticker
+	if len(args) == 0 {
+		var err error
+		if ts, err = cl.c.Tickers(); err != nil {
+			return err
+		}
+	}
+	for _, pair := range args {
+		t, err := cl.c.Ticker(pair)
+		if err != nil {
+			return err
+		}
+		ts = append(ts, t)
+	}
+
+	t := newTable("pair", "timestamp", "bid", "ask", "last_trade",
+		"volume_24h")
+	for _, tk := range ts {
+		t.add(tk.Pair, formatTime(tk.Timestamp), tk.Bid.String(),
+			tk.Ask.String(), tk.Last.String(), tk.Volume24H.String())
+	}
+	return cl.print(t)
+}
+
+func (cl *cli) orderBook(args []string) error {
+	if err := wantArgs(args, 1, "orderbook pair"); err != nil {
+		return err
+	}
+	bids, asks, err := cl.c.OrderBook(args[0])
+	if err != nil {
+		return err
+	}
+
+	t := newTable("side", "price", "volume")
+	for _, e := range asks {
+		t.add("ASK", e.Price.String(), e.Volume.String())
+	}
+	for _, e := range bids {
+		t.add("BID", e.Price.String(), e.Volume.String())
+	}
+	return cl.print(t)
+}
+
+func (cl *cli) trades(args []string) error {
+	if err := wantArgs(args, 1, "trades pair"); err != nil {
+		return err
+	}
+	trades, err := cl.c.Trades(args[0])
+	if err != nil {
+		return err
+	}
+
+	t := newTable("timestamp", "price", "volume")
+	for _, tr := range trades {
+		t.add(formatTime(tr.Timestamp), tr.Price.String(), tr.Volume.String())
+	}
+	return cl.print(t)
+}
+
+func (cl *cli) balance(args []string) error {
+	bl, err := cl.c.Balances(args...)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("account_id", "asset", "balance", "reserved", "available",
+		"unconfirmed")
+	for _, b := range bl {
+		t.add(b.AccountId, b.Asset, b.Balance.String(), b.Reserved.String(),
+			(b.Balance - b.Reserved).String(), b.Unconfirmed.String())
+	}
+	return cl.print(t)
+}
+
+func (cl *cli) orders(args []string) error {
+	return dispatch(cl, orderCommands, "orders: ", args)
+}
+
+func orderTable(orders ...bitx.Order) *table {
+	t := newTable("id", "pair", "type", "state", "created_at",
+		"limit_price", "limit_volume", "base", "counter", "fee_base",
+		"fee_counter", "completed_at")
+	for _, o := range orders {
+		t.add(o.Id, o.Pair, string(o.Type), string(o.State),
+			formatTime(o.CreatedAt), o.LimitPrice.String(),
+			o.LimitVolume.String(), o.Base.String(), o.Counter.String(),
+			o.FeeBase.String(), o.FeeCounter.String(),
+			formatTime(o.CompletedAt))
+	}
+	return t
+}
+
+func (cl *cli) listOrders(args []string) error {
+	fs := flags("orders list")
+	pair := fs.String("pair", "", "Currency pair")
+	state := fs.String("state", "", "Order state, e.g. PENDING")
+	limit := fs.Int("limit", 0, "Maximum number of orders")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("orders list: %v", err)
+	}
+	if err := wantArgs(fs.Args(), 0,
+		"orders list [-pair p] [-state s] [-limit n]"); err != nil {
+		return err
+	}
+
+	orders, err := cl.c.ListOrdersWithOptions(bitx.ListOrdersOptions{
+		Pair:  *pair,
+		State: bitx.OrderState(strings.ToUpper(*state)),
+		Limit: *limit,
+	})
+	if err != nil {
+		return err
+	}
+	return cl.print(orderTable(orders...))
+}
+
+func (cl *cli) getOrder(args []string) error {
+	if err := wantArgs(args, 1, "orders get id"); err != nil {
+		return err
+	}
+	o, err := cl.c.GetOrder(args[0])
+	if err != nil {
+		return err
+	}
+	return cl.print(orderTable(*o))
+}
+
+func (cl *cli) postOrder(args []string) error {
+	const usage = "orders post pair BID|ASK volume price"
+	if err := wantArgs(args, 4, usage); err != nil {
+		return err
+	}
+	pair, typ := args[0], bitx.OrderType(strings.ToUpper(args[1]))
+	if typ != bitx.BID && typ != bitx.ASK {
+		return fmt.Errorf("usage: bitx %s", usage)
+	}
+	volume, err := bitx.ParseAmount(args[2])
+	if err != nil {
+		return fmt.Errorf("volume %q: %v", args[2], err)
+	}
+	price, err := bitx.ParseAmount(args[3])
+	if err != nil {
+		return fmt.Errorf("price %q: %v", args[3], err)
+	}
+
+	if err := cl.confirm("Place %s order in %s for %s at %s",
+		typ, pair, volume, price); err != nil {
+		return err
+	}
+	id, err := cl.c.PostOrder(pair, typ, volume, price)
+	if err != nil {
+		return err
+	}
+	t := newTable("id")
+	t.add(id)
+	return cl.print(t)
+}
+
+func (cl *cli) stopOrder(args []string) error {
+	if err := wantArgs(args, 1, "orders stop id"); err != nil {
+		return err
+	}
+	if err := cl.confirm("Stop order %s", args[0]); err != nil {
+		return err
+	}
+	if err := cl.c.StopOrder(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cl.errOut, "Stopped order %s\n", args[0])
+	return nil
+}
+
+func (cl *cli) send(args []string) error {
+	fs := flags("send")
+	desc := fs.String("description", "", "Description for the transaction")
+	msg := fs.String("message", "", "Message for the recipient")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("send: %v", err)
+	}
+	args = fs.Args()
+	if err := wantArgs(args, 3, "send [-description d] [-message m] "+
+		"amount currency address"); err != nil {
+		return err
+	}
+	amount, err := bitx.ParseAmount(args[0])
+	if err != nil {
+		return fmt.Errorf("amount %q: %v", args[0], err)
+	}
+	currency, address := strings.ToUpper(args[1]), args[2]
+
+	if err := cl.confirm("Send %s %s to %s", amount, currency,
+		address); err != nil {
+		return err
+	}
+	err = cl.c.Send(amount.String(), currency, address, *desc, *msg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cl.errOut, "Sent %s %s to %s\n", amount, currency, address)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// config holds the API key and URL.
+type config struct {
+	APIKeyID     string `json:"api_key_id"`
+	APIKeySecret string `json:"api_key_secret"`
+	APIURL       string `json:"api_url"`
+}
+
+// loadConfig reads the config file at path, or at BITX_CONFIG or
+// $HOME/.bitx.json if path is empty, and applies any environment variables.
+// Only a missing default config file is ignored.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	var conf config
+	if path == "" {
+		path = getenv("BITX_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit && getenv("HOME") != "" {
+		path = filepath.Join(getenv("HOME"), ".bitx.json")
+	}
+
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil && (explicit || !os.IsNotExist(err)) {
+			return conf, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(b, &conf); err != nil {
+				return conf, fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+
+	for _, v := range []struct {
+		name  string
+		field *string
+	}{
+		{"BITX_API_KEY_ID", &conf.APIKeyID},
+		{"BITX_API_KEY_SECRET", &conf.APIKeySecret},
+		{"BITX_API_URL", &conf.APIURL},
+	} {
+		if s := getenv(v.name); s != "" {
+			*v.field = s
+		}
+	}
+	return conf, nil
+}
@@ -0,0 +1,117 @@
+// Command bitx is a command-line client for the BitX API.
+//
+// Usage:
+//
+//	bitx [flags] command [arguments]
+//
+// The commands are:
+//
+//	ticker [pair...]                      show tickers, all pairs by default
+//	orderbook pair                        show the order book of a pair
+//	trades pair                           show recent trades in a pair
+//	balance [asset...]                    show account balances
+//	orders list [-pair p] [-state s] [-limit n]
+//	                                      list orders, most recent first
+//	orders get id                         show an order
+//	orders post pair BID|ASK volume price place a limit order
+//	orders stop id                        stop an order
+//	send [-description d] [-message m] amount currency address
+//	                                      send funds to an address
+//
+// Output is a table by default, or JSON or CSV with -format. Commands which
+// place or stop orders or send funds ask for confirmation on standard input
+// first and only proceed if the answer is y.
+//
+// The API key is read from the BITX_API_KEY_ID and BITX_API_KEY_SECRET
+// environment variables, or from a JSON config file:
+//
+//	{"api_key_id": "...", "api_key_secret": "...", "api_url": "..."}
+//
+// The config file is $HOME/.bitx.json unless -config or BITX_CONFIG names
+// another. Environment variables override the file.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+var configPath = flag.String("config", "", "Path of the config file")
+var format = flag.String("format", "table", "Output format: table, json or csv")
+var apiURL = flag.String("api_url", "", "Base URL of the API, if not the default")
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bitx [flags] command [arguments]")
+	fmt.Fprintln(os.Stderr, "Run 'go doc bitx/cmd/bitx' for the commands.")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	conf, err := loadConfig(*configPath, os.Getenv)
+	if err != nil {
+		fatal(err)
+	}
+	if *apiURL != "" {
+		conf.APIURL = *apiURL
+	}
+	c, err := newClient(conf)
+	if err != nil {
+		fatal(err)
+	}
+
+	cl := &cli{
+		c:      c,
+		format: *format,
+		in:     bufio.NewReader(os.Stdin),
+		out:    os.Stdout,
+		errOut: os.Stderr,
+	}
+	if err := cl.run(flag.Args()); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "bitx:", err)
+	os.Exit(1)
+}
+
+func newClient(conf config) (*bitx.Client, error) {
+	opts := []bitx.Option{
+		bitx.WithUserAgent("bitx-cli"),
+		bitx.WithTimeout(30 * time.Second),
+		bitx.WithRetry(3, time.Second),
+	}
+	if conf.APIURL != "" {
+		u, err := url.Parse(conf.APIURL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, bitx.WithBaseURL(u))
+	}
+	return bitx.NewClient(conf.APIKeyID, conf.APIKeySecret, opts...), nil
+}
+
+// cli runs commands with a client.
+type cli struct {
+	c      *bitx.Client
+	format string
+	in     *bufio.Reader
+	// out receives command output and errOut prompts and notes, so that
+	// output can be piped.
+	out, errOut io.Writer
+}
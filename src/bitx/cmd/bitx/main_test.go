@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitx/bitx-go"
+	"github.com/bitx/bitx-go/bitxtest"
+)
+
+var amount = bitx.MustParseAmount
+
+// runCLI runs a command against the fake exchange with the given input and
+// returns its output.
+func runCLI(s *bitxtest.Server, format, input string, args ...string) (
+	string, error) {
+	var out bytes.Buffer
+	cl := &cli{
+		c:      s.Client(),
+		format: format,
+		in:     bufio.NewReader(strings.NewReader(input)),
+		out:    &out,
+		errOut: ioutil.Discard,
+	}
+	err := cl.run(args)
+	return out.String(), err
+}
+
+func TestOutputFormats(t *testing.T) {
+	s := bitxtest.NewServer()
+	defer s.Close()
+	s.SetBalance("XBT", amount("1.5"))
+
+	out, err := runCLI(s, "table", "", "balance", "XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ACCOUNT_ID") ||
+		!strings.Contains(lines[1], "1.5") {
+		t.Errorf("Unexpected table:\n%s", out)
+	}
+
+	out, err = runCLI(s, "csv", "", "balance", "XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "account_id,asset,balance,reserved,available,unconfirmed\n" +
+		s.AccountID("XBT") + ",XBT,1.5,0,1.5,0\n"
+	if out != want {
+		t.Errorf("Expected CSV\n%s\ngot\n%s", want, out)
+	}
+
+	out, err = runCLI(s, "json", "", "balance", "XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["balance"] != "1.5" {
+		t.Errorf("Unexpected JSON %s", out)
+	}
+
+	if _, err := runCLI(s, "xml", "", "balance"); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
+
+func TestOrderCommands(t *testing.T) {
+	s := bitxtest.NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", 1000*bitx.Unit)
+
+	args := []string{"orders", "post", "XBTZAR", "bid", "0.01", "100"}
+	if _, err := runCLI(s, "csv", "\n", args...); err != errAborted {
+		t.Errorf("Expected %v without confirmation, got %v", errAborted, err)
+	}
+	if _, err := runCLI(s, "csv", "", args...); err != errAborted {
+		t.Errorf("Expected %v at end of input, got %v", errAborted, err)
+	}
+	if b, r := s.Balance("ZAR"); r != 0 {
+		t.Fatalf("Expected no order to be placed, got balance %s reserved %s",
+			b, r)
+	}
+
+	out, err := runCLI(s, "csv", "y\n", args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strings.TrimPrefix(strings.TrimSpace(out), "id\n")
+
+	out, err = runCLI(s, "csv", "", "orders", "list", "-state", "pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, id+",XBTZAR,BID,PENDING") {
+		t.Errorf("Expected pending order %s in\n%s", id, out)
+	}
+
+	if _, err := runCLI(s, "csv", "yes\n", "orders", "stop", id); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runCLI(s, "csv", "", "orders", "get", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, ",COMPLETE,") {
+		t.Errorf("Expected stopped order, got\n%s", out)
+	}
+
+	if _, err := runCLI(s, "csv", "", "orders", "cancel", id); err == nil {
+		t.Errorf("Expected error for unknown command")
+	}
+}
+
+func TestSend(t *testing.T) {
+	s := bitxtest.NewServer()
+	defer s.Close()
+	s.SetBalance("XBT", 1*bitx.Unit)
+
+	args := []string{"send", "-description", "rent", "0.1", "xbt", "addr"}
+	if _, err := runCLI(s, "table", "n\n", args...); err != errAborted {
+		t.Errorf("Expected %v, got %v", errAborted, err)
+	}
+	if _, err := runCLI(s, "table", "y\n", args...); err != nil {
+		t.Fatal(err)
+	}
+	sends := s.Sends()
+	if len(sends) != 1 || sends[0].Amount != "0.1" ||
+		sends[0].Currency != "XBT" || sends[0].Description != "rent" {
+		t.Errorf("Unexpected sends %+v", sends)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bitx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".bitx.json")
+	err = ioutil.WriteFile(path,
+		[]byte(`{"api_key_id":"file_id","api_key_secret":"file_secret"}`),
+		0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"HOME": dir, "BITX_API_KEY_SECRET": "env_secret"}
+	getenv := func(k string) string { return env[k] }
+	conf, err := loadConfig("", getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := config{APIKeyID: "file_id", APIKeySecret: "env_secret"}
+	if conf != want {
+		t.Errorf("Expected %+v, got %+v", want, conf)
+	}
+
+	env["HOME"] = filepath.Join(dir, "missing")
+	if _, err := loadConfig("", getenv); err != nil {
+		t.Errorf("Expected missing default config to be ignored, got %v", err)
+	}
+	if _, err := loadConfig(filepath.Join(dir, "missing.json"),
+		getenv); err == nil {
+		t.Errorf("Expected error for missing config file")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// table is command output with named columns.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func newTable(header ...string) *table {
+	return &table{header: header}
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// print writes a table in the output format.
+func (cl *cli) print(t *table) error {
+	switch cl.format {
+	case "table":
+		w := tabwriter.NewWriter(cl.out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(t.header, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+
+	case "csv":
+		w := csv.NewWriter(cl.out)
+		w.Write(t.header)
+		w.WriteAll(t.rows)
+		return w.Error()
+
+	case "json":
+		// Rows are objects keyed by column. Amounts stay strings so that
+		// they are exact.
+		l := make([]map[string]string, len(t.rows))
+		for i, row := range t.rows {
+			l[i] = make(map[string]string, len(row))
+			for j, cell := range row {
+				l[i][t.header[j]] = cell
+			}
+		}
+		b, err := json.MarshalIndent(l, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cl.out, "%s\n", b)
+		return err
+	}
+	return fmt.Errorf("unknown format %q", cl.format)
+}
+
+// formatTime formats a time in UTC with milliseconds, or returns "" for the
+// zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"testing"
+
+	"bitx/streamer/matching"
+	"bitx/streamer/streamerpb"
+)
+
+func TestHandleUpdatesFromMatchingEngine(t *testing.T) {
+	e := matching.New()
+	e.Limit(streamerpb.Order_BID, 100e8, 2e8)
+	ob, err := makeOrderBook(e.OrderBook())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var updates []*streamerpb.Update
+	record := func(_ int64, u *streamerpb.Update, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		updates = append(updates, u)
+	}
+	record(e.Limit(streamerpb.Order_BID, 101e8, 1e8))
+	record(e.Limit(streamerpb.Order_ASK, 102e8, 1e8))
+	record(e.Limit(streamerpb.Order_ASK, 100e8, 15e7))
+	record(e.Market(streamerpb.Order_BID, 5e7))
+	u, err := e.Cancel(1)
+	record(0, u, err)
+
+	for _, u := range updates {
+		if err := ob.handleUpdate(u); err != nil {
+			t.Fatalf("Update %d: %v", u.Sequence, err)
+		}
+	}
+
+	want := e.OrderBook()
+	if ob.sequence != want.Sequence {
+		t.Errorf("Expected sequence %d, got %d", want.Sequence, ob.sequence)
+	}
+	if len(ob.Bids) != len(want.Bids) || len(ob.Asks) != len(want.Asks) {
+		t.Fatalf("Expected %v, got %v", want, ob)
+	}
+	for _, o := range append(want.Bids, want.Asks...) {
+		got, ok := ob.Bids[o.OrderId]
+		if !ok {
+			got, ok = ob.Asks[o.OrderId]
+		}
+		if !ok || got.volume != o.VolumeE8 || got.price != o.PriceE8 {
+			t.Errorf("Expected order %v, got %+v", o, got)
+		}
+	}
+}
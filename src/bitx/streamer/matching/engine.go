@@ -0,0 +1,308 @@
+// Package matching implements an in-process limit order book with
+// price-time priority matching. It emits the same streamerpb updates as the
+// streamer server, so it can be used as a local source of truth for the
+// server, the client order book and trading bots.
+package matching
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"bitx/streamer/streamerpb"
+)
+
+// ErrOrderNotFound indicates that the order isn't in the order book.
+var ErrOrderNotFound = errors.New("Order not found")
+
+// ErrUnknownOrderType indicates the type of order is neither bid nor ask.
+var ErrUnknownOrderType = errors.New("Unknown order type")
+
+// ErrInvalidPrice indicates that a limit order has a non-positive price.
+var ErrInvalidPrice = errors.New("Invalid price")
+
+// ErrInvalidVolume indicates that an order has a non-positive volume.
+var ErrInvalidVolume = errors.New("Invalid volume")
+
+// ErrSourceTooSlow indicates that a source was dropped because its updates
+// weren't being received.
+var ErrSourceTooSlow = errors.New("Source fell too far behind")
+
+// sourceBuffer is the number of updates that may be queued for a source
+// before it is dropped.
+const sourceBuffer = 1024
+
+// Engine is a limit order book for a single pair. It is safe for concurrent
+// use.
+type Engine struct {
+	mu       sync.Mutex
+	sequence int64
+	lastID   int64
+	// bids and asks are sorted by priority: best price first, then oldest
+	// order first.
+	bids    []*streamerpb.Order
+	asks    []*streamerpb.Order
+	sources []*Source
+}
+
+// New returns an engine with an empty order book.
+func New() *Engine {
+	return &Engine{}
+}
+
+// Limit places a limit order. The order is matched against resting orders on
+// the other side of the book and any remaining volume rests in the book.
+// It returns the ID of the new order and the resulting update.
+func (e *Engine) Limit(typ streamerpb.Order_Type, priceE8, volumeE8 int64) (
+	int64, *streamerpb.Update, error) {
+	if typ != streamerpb.Order_BID && typ != streamerpb.Order_ASK {
+		return 0, nil, ErrUnknownOrderType
+	}
+	if priceE8 <= 0 {
+		return 0, nil, ErrInvalidPrice
+	}
+	if volumeE8 <= 0 {
+		return 0, nil, ErrInvalidVolume
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastID++
+	o := &streamerpb.Order{
+		Type:     typ,
+		OrderId:  e.lastID,
+		PriceE8:  priceE8,
+		VolumeE8: volumeE8,
+	}
+
+	u := &streamerpb.Update{TradeUpdate: e.match(o, true)}
+	if o.VolumeE8 > 0 {
+		e.insert(o)
+		c := *o
+		u.CreateUpdate = &streamerpb.CreateUpdate{Order: &c}
+	}
+	e.publish(u)
+
+	return o.OrderId, u, nil
+}
+
+// Market places a market order for the given base volume. The order is
+// matched against resting orders on the other side of the book and any
+// volume which cannot be filled is discarded. It returns the ID of the order
+// and the resulting update, which is nil if nothing was filled.
+func (e *Engine) Market(typ streamerpb.Order_Type, volumeE8 int64) (
+	int64, *streamerpb.Update, error) {
+	if typ != streamerpb.Order_BID && typ != streamerpb.Order_ASK {
+		return 0, nil, ErrUnknownOrderType
+	}
+	if volumeE8 <= 0 {
+		return 0, nil, ErrInvalidVolume
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastID++
+	o := &streamerpb.Order{
+		Type:     typ,
+		OrderId:  e.lastID,
+		VolumeE8: volumeE8,
+	}
+
+	trades := e.match(o, false)
+	if len(trades) == 0 {
+		return o.OrderId, nil, nil
+	}
+	u := &streamerpb.Update{TradeUpdate: trades}
+	e.publish(u)
+
+	return o.OrderId, u, nil
+}
+
+// Cancel removes a resting order from the book.
+func (e *Engine) Cancel(id int64) (*streamerpb.Update, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.remove(&e.bids, id) && !e.remove(&e.asks, id) {
+		return nil, ErrOrderNotFound
+	}
+	u := &streamerpb.Update{
+		DeleteUpdate: &streamerpb.DeleteUpdate{OrderId: id},
+	}
+	e.publish(u)
+
+	return u, nil
+}
+
+// match fills o against the other side of the book. If limit is false, the
+// order's price is ignored.
+func (e *Engine) match(o *streamerpb.Order, limit bool) []*streamerpb.TradeUpdate {
+	book := &e.asks
+	crosses := func(price int64) bool { return price <= o.PriceE8 }
+	if o.Type == streamerpb.Order_ASK {
+		book = &e.bids
+		crosses = func(price int64) bool { return price >= o.PriceE8 }
+	}
+
+	var trades []*streamerpb.TradeUpdate
+	for o.VolumeE8 > 0 && len(*book) > 0 {
+		maker := (*book)[0]
+		if limit && !crosses(maker.PriceE8) {
+			break
+		}
+		fill := o.VolumeE8
+		if maker.VolumeE8 < fill {
+			fill = maker.VolumeE8
+		}
+		trades = append(trades, &streamerpb.TradeUpdate{
+			OrderId:   maker.OrderId,
+			BaseE8:    fill,
+			CounterE8: counterE8(fill, maker.PriceE8),
+		})
+		o.VolumeE8 -= fill
+		maker.VolumeE8 -= fill
+		if maker.VolumeE8 == 0 {
+			*book = (*book)[1:]
+		}
+	}
+	return trades
+}
+
+// counterE8 returns base * price, both in units of 1e-8.
+func counterE8(base, price int64) int64 {
+	c := new(big.Int).Mul(big.NewInt(base), big.NewInt(price))
+	return c.Quo(c, big.NewInt(1e8)).Int64()
+}
+
+// insert adds a resting order behind all orders at the same or better price.
+func (e *Engine) insert(o *streamerpb.Order) {
+	book := &e.bids
+	after := func(other *streamerpb.Order) bool { return other.PriceE8 < o.PriceE8 }
+	if o.Type == streamerpb.Order_ASK {
+		book = &e.asks
+		after = func(other *streamerpb.Order) bool { return other.PriceE8 > o.PriceE8 }
+	}
+
+	i := sort.Search(len(*book), func(i int) bool { return after((*book)[i]) })
+	*book = append(*book, nil)
+	copy((*book)[i+1:], (*book)[i:])
+	(*book)[i] = o
+}
+
+func (e *Engine) remove(book *[]*streamerpb.Order, id int64) bool {
+	for i, o := range *book {
+		if o.OrderId == id {
+			*book = append((*book)[:i], (*book)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// publish assigns the update the next sequence number and sends it to all
+// sources without blocking. Sources with full buffers are dropped, since
+// they would miss the update. It must be called with e.mu held.
+func (e *Engine) publish(u *streamerpb.Update) {
+	e.sequence++
+	u.Sequence = e.sequence
+	live := e.sources[:0]
+	for _, s := range e.sources {
+		select {
+		case s.updates <- u:
+			live = append(live, s)
+		default:
+			s.err = ErrSourceTooSlow
+			close(s.updates)
+		}
+	}
+	for i := len(live); i < len(e.sources); i++ {
+		e.sources[i] = nil
+	}
+	e.sources = live
+}
+
+func copyOrders(orders []*streamerpb.Order) []*streamerpb.Order {
+	r := make([]*streamerpb.Order, len(orders))
+	for i, o := range orders {
+		c := *o
+		r[i] = &c
+	}
+	return r
+}
+
+// OrderBook returns a copy of the order book. Orders are sorted by priority.
+func (e *Engine) OrderBook() *streamerpb.OrderBook {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.orderBook()
+}
+
+func (e *Engine) orderBook() *streamerpb.OrderBook {
+	return &streamerpb.OrderBook{
+		Sequence: e.sequence,
+		Bids:     copyOrders(e.bids),
+		Asks:     copyOrders(e.asks),
+	}
+}
+
+// Source is a stream of the engine's updates. It implements server.Source.
+type Source struct {
+	e       *Engine
+	ob      *streamerpb.OrderBook
+	updates chan *streamerpb.Update
+	// err is set under e.mu when the source is dropped.
+	err error
+}
+
+// Source returns a new stream starting from the current state of the book.
+// If sourceBuffer updates are waiting to be received, the source is dropped
+// and its Updates channel closed, so the source's updates must be consumed.
+// Call Stop when the source is no longer needed.
+func (e *Engine) Source() *Source {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := &Source{
+		e:       e,
+		ob:      e.orderBook(),
+		updates: make(chan *streamerpb.Update, sourceBuffer),
+	}
+	e.sources = append(e.sources, s)
+	return s
+}
+
+// Stop unsubscribes the source from the engine and closes its Updates
+// channel. It is safe to call more than once.
+func (s *Source) Stop() {
+	e := s.e
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, o := range e.sources {
+		if o == s {
+			e.sources = append(e.sources[:i], e.sources[i+1:]...)
+			close(s.updates)
+			return
+		}
+	}
+}
+
+// Err returns ErrSourceTooSlow if the source was dropped for falling behind,
+// or nil otherwise.
+func (s *Source) Err() error {
+	s.e.mu.Lock()
+	defer s.e.mu.Unlock()
+	return s.err
+}
+
+// OrderBook returns the order book at the time the source was created.
+func (s *Source) OrderBook() (*streamerpb.OrderBook, error) {
+	return s.ob, nil
+}
+
+// Updates returns the channel of updates.
+func (s *Source) Updates() <-chan *streamerpb.Update {
+	return s.updates
+}
@@ -0,0 +1,197 @@
+package matching
+
+import (
+	"reflect"
+	"testing"
+
+	"bitx/streamer/streamerpb"
+)
+
+const (
+	bid = streamerpb.Order_BID
+	ask = streamerpb.Order_ASK
+)
+
+func limit(t *testing.T, e *Engine, typ streamerpb.Order_Type,
+	price, volume int64) (int64, *streamerpb.Update) {
+	id, u, err := e.Limit(typ, price, volume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id, u
+}
+
+func ids(orders []*streamerpb.Order) []int64 {
+	r := make([]int64, len(orders))
+	for i, o := range orders {
+		r[i] = o.OrderId
+	}
+	return r
+}
+
+func TestPriceTimePriority(t *testing.T) {
+	e := New()
+	b1, _ := limit(t, e, bid, 100, 1)
+	b2, _ := limit(t, e, bid, 101, 1)
+	b3, _ := limit(t, e, bid, 100, 1)
+	a1, _ := limit(t, e, ask, 110, 1)
+	a2, _ := limit(t, e, ask, 109, 1)
+	a3, _ := limit(t, e, ask, 110, 1)
+
+	ob := e.OrderBook()
+	if want := []int64{b2, b1, b3}; !reflect.DeepEqual(ids(ob.Bids), want) {
+		t.Errorf("Expected bids %v, got %v", want, ids(ob.Bids))
+	}
+	if want := []int64{a2, a1, a3}; !reflect.DeepEqual(ids(ob.Asks), want) {
+		t.Errorf("Expected asks %v, got %v", want, ids(ob.Asks))
+	}
+	if ob.Sequence != 6 {
+		t.Errorf("Expected sequence 6, got %d", ob.Sequence)
+	}
+}
+
+func TestLimitCrosses(t *testing.T) {
+	e := New()
+	a1, _ := limit(t, e, ask, 100e8, 1e8)
+	a2, _ := limit(t, e, ask, 101e8, 1e8)
+	limit(t, e, ask, 102e8, 1e8)
+
+	id, u := limit(t, e, bid, 101e8, 3e8)
+	want := &streamerpb.Update{
+		Sequence: 4,
+		TradeUpdate: []*streamerpb.TradeUpdate{
+			{OrderId: a1, BaseE8: 1e8, CounterE8: 100e8},
+			{OrderId: a2, BaseE8: 1e8, CounterE8: 101e8},
+		},
+		CreateUpdate: &streamerpb.CreateUpdate{Order: &streamerpb.Order{
+			Type: bid, OrderId: id, PriceE8: 101e8, VolumeE8: 1e8,
+		}},
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("Expected %v, got %v", want, u)
+	}
+
+	ob := e.OrderBook()
+	if len(ob.Bids) != 1 || len(ob.Asks) != 1 {
+		t.Errorf("Expected one bid and one ask, got %v", ob)
+	}
+}
+
+func TestPartialFillOfMaker(t *testing.T) {
+	e := New()
+	b1, _ := limit(t, e, bid, 100, 5)
+	_, u := limit(t, e, ask, 99, 2)
+	if u.CreateUpdate != nil {
+		t.Errorf("Expected ask to be filled, got %v", u)
+	}
+	ob := e.OrderBook()
+	if len(ob.Bids) != 1 || ob.Bids[0].OrderId != b1 || ob.Bids[0].VolumeE8 != 3 {
+		t.Errorf("Expected bid %d with volume 3, got %v", b1, ob.Bids)
+	}
+}
+
+func TestMarket(t *testing.T) {
+	e := New()
+	limit(t, e, bid, 100, 1)
+	limit(t, e, bid, 90, 1)
+
+	_, u, err := e.Market(ask, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(u.TradeUpdate) != 2 || u.CreateUpdate != nil {
+		t.Errorf("Expected two trades and no create, got %v", u)
+	}
+	if ob := e.OrderBook(); len(ob.Bids) != 0 {
+		t.Errorf("Expected empty book, got %v", ob)
+	}
+
+	_, u, err = e.Market(ask, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != nil {
+		t.Errorf("Expected no update for unfilled market order, got %v", u)
+	}
+	if ob := e.OrderBook(); ob.Sequence != 3 {
+		t.Errorf("Expected sequence 3, got %d", ob.Sequence)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	e := New()
+	id, _ := limit(t, e, bid, 100, 1)
+	u, err := e.Cancel(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.DeleteUpdate == nil || u.DeleteUpdate.OrderId != id {
+		t.Errorf("Expected delete of %d, got %v", id, u)
+	}
+	if _, err := e.Cancel(id); err != ErrOrderNotFound {
+		t.Errorf("Expected %v, got %v", ErrOrderNotFound, err)
+	}
+}
+
+func TestInvalidOrders(t *testing.T) {
+	e := New()
+	if _, _, err := e.Limit(streamerpb.Order_UNKNOWN, 1, 1); err != ErrUnknownOrderType {
+		t.Errorf("Expected %v, got %v", ErrUnknownOrderType, err)
+	}
+	if _, _, err := e.Limit(bid, 0, 1); err != ErrInvalidPrice {
+		t.Errorf("Expected %v, got %v", ErrInvalidPrice, err)
+	}
+	if _, _, err := e.Market(ask, 0); err != ErrInvalidVolume {
+		t.Errorf("Expected %v, got %v", ErrInvalidVolume, err)
+	}
+}
+
+func TestSource(t *testing.T) {
+	e := New()
+	limit(t, e, bid, 100, 1)
+	s := e.Source()
+	ob, _ := s.OrderBook()
+	if ob.Sequence != 1 || len(ob.Bids) != 1 {
+		t.Errorf("Expected one bid at sequence 1, got %v", ob)
+	}
+	limit(t, e, ask, 100, 1)
+	u := <-s.Updates()
+	if u.Sequence != 2 || len(u.TradeUpdate) != 1 {
+		t.Errorf("Expected trade at sequence 2, got %v", u)
+	}
+}
+
+func TestSourceStop(t *testing.T) {
+	e := New()
+	s := e.Source()
+	s.Stop()
+	s.Stop()
+	limit(t, e, bid, 100, 1)
+	if _, ok := <-s.Updates(); ok {
+		t.Errorf("Expected no updates after Stop")
+	}
+	if s.Err() != nil {
+		t.Errorf("Expected no error after Stop, got %v", s.Err())
+	}
+}
+
+func TestSlowSourceDropped(t *testing.T) {
+	e := New()
+	slow, fast := e.Source(), e.Source()
+	for i := 0; i <= sourceBuffer; i++ {
+		limit(t, e, bid, 100, 1)
+		<-fast.Updates()
+	}
+	n := 0
+	for range slow.Updates() {
+		n++
+	}
+	if n != sourceBuffer || slow.Err() != ErrSourceTooSlow {
+		t.Errorf("Expected %d updates and %v, got %d and %v", sourceBuffer,
+			ErrSourceTooSlow, n, slow.Err())
+	}
+	if fast.Err() != nil {
+		t.Errorf("Expected fast source to keep receiving, got %v", fast.Err())
+	}
+	slow.Stop()
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"bitx/streamer/streamerpb"
+)
+
+// subscriberBuffer is the number of updates that may be queued for a
+// subscriber before it is considered too slow and disconnected.
+const subscriberBuffer = 1024
+
+// ErrOrderNotFound indicates that an update refers to an order which isn't in
+// the order book.
+var ErrOrderNotFound = errors.New("Order not found")
+
+// ErrUnknownOrderType indicates the type of order is neither bid nor ask.
+var ErrUnknownOrderType = errors.New("Unknown order type")
+
+var errSourceClosed = grpc.Errorf(codes.Unavailable, "source closed")
+var errTooSlow = grpc.Errorf(codes.ResourceExhausted,
+	"subscriber fell too far behind")
+
+type subscriber struct {
+	updates chan *streamerpb.Update
+	// err is set before updates is closed.
+	err error
+}
+
+// market holds the order book and subscribers for a single pair.
+type market struct {
+	pair string
+
+	mu       sync.Mutex
+	sequence int64
+	bids     map[int64]*streamerpb.Order
+	asks     map[int64]*streamerpb.Order
+	subs     map[*subscriber]bool
+	closed   bool
+}
+
+func newMarket(pair string, ob *streamerpb.OrderBook) *market {
+	m := &market{
+		pair:     pair,
+		sequence: ob.Sequence,
+		bids:     make(map[int64]*streamerpb.Order),
+		asks:     make(map[int64]*streamerpb.Order),
+		subs:     make(map[*subscriber]bool),
+	}
+	for _, o := range ob.Bids {
+		m.bids[o.OrderId] = copyOrder(o)
+	}
+	for _, o := range ob.Asks {
+		m.asks[o.OrderId] = copyOrder(o)
+	}
+	return m
+}
+
+func copyOrder(o *streamerpb.Order) *streamerpb.Order {
+	c := *o
+	return &c
+}
+
+// run publishes updates until the channel is closed.
+func (m *market) run(updates <-chan *streamerpb.Update) {
+	for u := range updates {
+		m.publish(u)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	for sub := range m.subs {
+		m.drop(sub, errSourceClosed)
+	}
+	log.Printf("bitx/streamer/server.market.run: Source for %s closed.",
+		m.pair)
+}
+
+// publish applies the update to the order book, assigns it the next sequence
+// number and sends it to all subscribers.
+func (m *market) publish(u *streamerpb.Update) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.apply(u); err != nil {
+		log.Printf("bitx/streamer/server.market.publish: %s: %v", m.pair, err)
+	}
+
+	m.sequence++
+	pub := *u
+	pub.Sequence = m.sequence
+
+	for sub := range m.subs {
+		select {
+		case sub.updates <- &pub:
+		default:
+			log.Printf("bitx/streamer/server.market.publish: %s: Dropping "+
+				"slow subscriber.", m.pair)
+			m.drop(sub, errTooSlow)
+		}
+	}
+}
+
+// apply applies the update to the order book in the same order as the
+// client: trades, then create, then delete.
+func (m *market) apply(u *streamerpb.Update) error {
+	for _, t := range u.GetTradeUpdate() {
+		o, ok := m.asks[t.OrderId]
+		if !ok {
+			o, ok = m.bids[t.OrderId]
+			if !ok {
+				return ErrOrderNotFound
+			}
+		}
+		o.VolumeE8 -= t.BaseE8
+		if o.VolumeE8 <= 0 {
+			m.remove(t.OrderId)
+		}
+	}
+
+	if c := u.GetCreateUpdate(); c != nil && c.Order != nil {
+		switch c.Order.Type {
+		case streamerpb.Order_BID:
+			m.bids[c.Order.OrderId] = copyOrder(c.Order)
+		case streamerpb.Order_ASK:
+			m.asks[c.Order.OrderId] = copyOrder(c.Order)
+		default:
+			return ErrUnknownOrderType
+		}
+	}
+
+	if d := u.GetDeleteUpdate(); d != nil {
+		m.remove(d.OrderId)
+	}
+
+	return nil
+}
+
+func (m *market) remove(id int64) {
+	delete(m.bids, id)
+	delete(m.asks, id)
+}
+
+// snapshot returns a copy of the order book. Bids are sorted by descending
+// and asks by ascending price.
+func (m *market) snapshot() *streamerpb.OrderBook {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ob := &streamerpb.OrderBook{
+		Sequence: m.sequence,
+		Bids:     sortedOrders(m.bids, true),
+		Asks:     sortedOrders(m.asks, false),
+	}
+	return ob
+}
+
+type byPrice struct {
+	orders []*streamerpb.Order
+	desc   bool
+}
+
+func (b byPrice) Len() int      { return len(b.orders) }
+func (b byPrice) Swap(i, j int) { b.orders[i], b.orders[j] = b.orders[j], b.orders[i] }
+func (b byPrice) Less(i, j int) bool {
+	oi, oj := b.orders[i], b.orders[j]
+	if oi.PriceE8 != oj.PriceE8 {
+		return (oi.PriceE8 < oj.PriceE8) != b.desc
+	}
+	return oi.OrderId < oj.OrderId
+}
+
+func sortedOrders(orders map[int64]*streamerpb.Order,
+	desc bool) []*streamerpb.Order {
+	l := make([]*streamerpb.Order, 0, len(orders))
+	for _, o := range orders {
+		l = append(l, copyOrder(o))
+	}
+	sort.Sort(byPrice{l, desc})
+	return l
+}
+
+func (m *market) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.bids) + len(m.asks)
+}
+
+func (m *market) subscribe() *subscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &subscriber{updates: make(chan *streamerpb.Update, subscriberBuffer)}
+	if m.closed {
+		sub.err = errSourceClosed
+		close(sub.updates)
+		return sub
+	}
+	m.subs[sub] = true
+	return sub
+}
+
+func (m *market) unsubscribe(sub *subscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subs, sub)
+}
+
+// drop disconnects a subscriber. It must be called with m.mu held.
+func (m *market) drop(sub *subscriber, err error) {
+	if !m.subs[sub] {
+		return
+	}
+	delete(m.subs, sub)
+	sub.err = err
+	close(sub.updates)
+}
+
+func (m *market) numSubscribers() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subs)
+}
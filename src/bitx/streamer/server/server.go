@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"bitx/streamer/streamerpb"
+)
+
+// Source is a source of market data for a single currency pair.
+type Source interface {
+	// OrderBook returns the state of the order book before the first update
+	// sent on the Updates channel.
+	OrderBook() (*streamerpb.OrderBook, error)
+
+	// Updates returns a channel of changes to the order book. The channel is
+	// closed when the source has no more updates. Sequence numbers set by the
+	// source are ignored; the server assigns its own.
+	Updates() <-chan *streamerpb.Update
+}
+
+// ErrPairExists indicates that a source has already been added for the pair.
+var ErrPairExists = errors.New("Pair already exists")
+
+// Server is a streamer gRPC server. It implements the service described by
+// streamerpb.proto and serves updates from one Source per currency pair.
+type Server struct {
+	mu sync.RWMutex
+	// markets holds a nil market for pairs which are still being added.
+	markets map[string]*market
+}
+
+// New returns a new server with no pairs.
+func New() *Server {
+	return &Server{markets: make(map[string]*market)}
+}
+
+// AddPair starts serving the given pair from src. It fetches the initial
+// order book from the source and then applies its updates in the background
+// until the source closes its Updates channel.
+func (s *Server) AddPair(pair string, src Source) error {
+	s.mu.Lock()
+	if _, ok := s.markets[pair]; ok {
+		s.mu.Unlock()
+		return ErrPairExists
+	}
+	// Reserve the pair while fetching the order book, which may be slow.
+	s.markets[pair] = nil
+	s.mu.Unlock()
+
+	ob, err := src.OrderBook()
+	if err != nil {
+		s.mu.Lock()
+		delete(s.markets, pair)
+		s.mu.Unlock()
+		return err
+	}
+
+	m := newMarket(pair, ob)
+	s.mu.Lock()
+	s.markets[pair] = m
+	s.mu.Unlock()
+	go m.run(src.Updates())
+
+	log.Printf("bitx/streamer/server.AddPair: Serving %s with %d order(s).",
+		pair, m.len())
+
+	return nil
+}
+
+func (s *Server) market(pair string) (*market, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := s.markets[pair]
+	if m == nil {
+		return nil, grpc.Errorf(codes.NotFound, "unknown pair %q", pair)
+	}
+	return m, nil
+}
+
+// Register registers the server with a gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	streamerpb.RegisterStreamerServer(gs, s)
+}
+
+// GetOrderBook returns the current order book for the requested pair.
+func (s *Server) GetOrderBook(ctx context.Context,
+	req *streamerpb.GetOrderBookRequest) (*streamerpb.OrderBook, error) {
+	m, err := s.market(req.Pair)
+	if err != nil {
+		return nil, err
+	}
+	return m.snapshot(), nil
+}
+
+// StreamUpdates streams updates for the requested pair until the client goes
+// away, the source is exhausted or the client falls too far behind.
+func (s *Server) StreamUpdates(req *streamerpb.StreamUpdatesRequest,
+	stream streamerpb.Streamer_StreamUpdatesServer) error {
+	m, err := s.market(req.Pair)
+	if err != nil {
+		return err
+	}
+
+	sub := m.subscribe()
+	defer m.unsubscribe(sub)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case u, ok := <-sub.updates:
+			if !ok {
+				return sub.err
+			}
+			if err := stream.Send(u); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"bitx/streamer/streamerpb"
+)
+
+type fakeSource struct {
+	ob      *streamerpb.OrderBook
+	updates chan *streamerpb.Update
+}
+
+func newFakeSource(ob *streamerpb.OrderBook) *fakeSource {
+	return &fakeSource{ob: ob, updates: make(chan *streamerpb.Update)}
+}
+
+func (f *fakeSource) OrderBook() (*streamerpb.OrderBook, error) {
+	return f.ob, nil
+}
+
+func (f *fakeSource) Updates() <-chan *streamerpb.Update {
+	return f.updates
+}
+
+func startServer(t *testing.T, s *Server) (streamerpb.StreamerClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	go gs.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return streamerpb.NewStreamerClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+	}
+}
+
+func waitForSubscribers(t *testing.T, m *market, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for m.numSubscribers() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d subscribers, got %d", n, m.numSubscribers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func bid(id, price, volume int64) *streamerpb.Order {
+	return &streamerpb.Order{Type: streamerpb.Order_BID, OrderId: id,
+		PriceE8: price, VolumeE8: volume}
+}
+
+func ask(id, price, volume int64) *streamerpb.Order {
+	return &streamerpb.Order{Type: streamerpb.Order_ASK, OrderId: id,
+		PriceE8: price, VolumeE8: volume}
+}
+
+func TestGetOrderBookUnknownPair(t *testing.T) {
+	cl, stop := startServer(t, New())
+	defer stop()
+
+	_, err := cl.GetOrderBook(context.Background(),
+		&streamerpb.GetOrderBookRequest{Pair: "XBTZAR"})
+	if err == nil {
+		t.Errorf("Expected error for unknown pair")
+	}
+}
+
+func TestAddPairTwice(t *testing.T) {
+	s := New()
+	src := newFakeSource(&streamerpb.OrderBook{})
+	if err := s.AddPair("XBTZAR", src); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPair("XBTZAR", src); err != ErrPairExists {
+		t.Errorf("Expected %v, got %v", ErrPairExists, err)
+	}
+}
+
+// slowSource blocks in OrderBook until release is closed.
+type slowSource struct {
+	*fakeSource
+	started, release chan struct{}
+}
+
+func (f *slowSource) OrderBook() (*streamerpb.OrderBook, error) {
+	close(f.started)
+	<-f.release
+	return f.fakeSource.OrderBook()
+}
+
+func TestAddPairDoesNotBlock(t *testing.T) {
+	s := New()
+	slow := &slowSource{newFakeSource(&streamerpb.OrderBook{}),
+		make(chan struct{}), make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- s.AddPair("XBTZAR", slow)
+	}()
+	<-slow.started
+
+	if err := s.AddPair("ETHXBT", newFakeSource(&streamerpb.OrderBook{})); err != nil {
+		t.Errorf("Expected to add another pair while fetching, got %v", err)
+	}
+	if err := s.AddPair("XBTZAR", newFakeSource(nil)); err != ErrPairExists {
+		t.Errorf("Expected %v while fetching, got %v", ErrPairExists, err)
+	}
+	if _, err := s.market("XBTZAR"); err == nil {
+		t.Errorf("Expected pair to be unavailable while fetching")
+	}
+
+	close(slow.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.market("XBTZAR"); err != nil {
+		t.Errorf("Expected pair to be served, got %v", err)
+	}
+}
+
+func TestStreamUpdates(t *testing.T) {
+	s := New()
+	src := newFakeSource(&streamerpb.OrderBook{
+		Sequence: 10,
+		Bids:     []*streamerpb.Order{bid(1, 100, 5)},
+		Asks:     []*streamerpb.Order{ask(2, 110, 5)},
+	})
+	if err := s.AddPair("XBTZAR", src); err != nil {
+		t.Fatal(err)
+	}
+	cl, stop := startServer(t, s)
+	defer stop()
+
+	const numClients = 3
+	streams := make([]streamerpb.Streamer_StreamUpdatesClient, numClients)
+	for i := range streams {
+		stream, err := cl.StreamUpdates(context.Background(),
+			&streamerpb.StreamUpdatesRequest{Pair: "XBTZAR"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams[i] = stream
+	}
+	m, _ := s.market("XBTZAR")
+	waitForSubscribers(t, m, numClients)
+
+	updates := []*streamerpb.Update{
+		{CreateUpdate: &streamerpb.CreateUpdate{Order: bid(3, 105, 1)}},
+		{TradeUpdate: []*streamerpb.TradeUpdate{
+			{OrderId: 2, BaseE8: 5, CounterE8: 550}}},
+		{DeleteUpdate: &streamerpb.DeleteUpdate{OrderId: 1}},
+	}
+	for _, u := range updates {
+		src.updates <- u
+	}
+
+	for i, stream := range streams {
+		for j := range updates {
+			u, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("Client %d: %v", i, err)
+			}
+			if want := int64(11 + j); u.Sequence != want {
+				t.Errorf("Client %d: expected sequence %d, got %d",
+					i, want, u.Sequence)
+			}
+		}
+	}
+
+	ob, err := cl.GetOrderBook(context.Background(),
+		&streamerpb.GetOrderBookRequest{Pair: "XBTZAR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ob.Sequence != 13 {
+		t.Errorf("Expected sequence 13, got %d", ob.Sequence)
+	}
+	if len(ob.Bids) != 1 || ob.Bids[0].OrderId != 3 {
+		t.Errorf("Expected only bid 3, got %v", ob.Bids)
+	}
+	if len(ob.Asks) != 0 {
+		t.Errorf("Expected no asks, got %v", ob.Asks)
+	}
+}
+
+func TestSnapshotSorted(t *testing.T) {
+	m := newMarket("XBTZAR", &streamerpb.OrderBook{
+		Bids: []*streamerpb.Order{bid(1, 100, 1), bid(2, 102, 1),
+			bid(3, 101, 1)},
+		Asks: []*streamerpb.Order{ask(4, 112, 1), ask(5, 110, 1),
+			ask(6, 111, 1)},
+	})
+	ob := m.snapshot()
+	for i, want := range []int64{2, 3, 1} {
+		if ob.Bids[i].OrderId != want {
+			t.Errorf("Expected bid %d at %d, got %d", want, i, ob.Bids[i].OrderId)
+		}
+	}
+	for i, want := range []int64{5, 6, 4} {
+		if ob.Asks[i].OrderId != want {
+			t.Errorf("Expected ask %d at %d, got %d", want, i, ob.Asks[i].OrderId)
+		}
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	m := newMarket("XBTZAR", &streamerpb.OrderBook{})
+	slow := m.subscribe()
+	for i := 0; i <= subscriberBuffer; i++ {
+		m.publish(&streamerpb.Update{
+			CreateUpdate: &streamerpb.CreateUpdate{Order: bid(int64(i), 1, 1)},
+		})
+	}
+	if m.numSubscribers() != 0 {
+		t.Errorf("Expected slow subscriber to be dropped")
+	}
+	if slow.err != errTooSlow {
+		t.Errorf("Expected %v, got %v", errTooSlow, slow.err)
+	}
+}
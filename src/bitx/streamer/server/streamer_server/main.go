@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/bitx/bitx-go"
+	"google.golang.org/grpc"
+
+	"bitx/streamer/server"
+	"bitx/streamer/snapshot"
+)
+
+var address = flag.String("address", ":50051", "Address to listen on")
+var pairs = flag.String("pairs", "XBTZAR", "Comma-separated markets to serve")
+var pollInterval = flag.Duration("poll_interval", 5*time.Second,
+	"Interval between order book polls")
+
+func main() {
+	flag.Parse()
+
+	c := bitx.NewClient("", "")
+	s := server.New()
+	for _, pair := range strings.Split(*pairs, ",") {
+		src := snapshot.NewSource(c, pair, *pollInterval)
+		if err := s.AddPair(pair, src); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	lis, err := net.Listen("tcp", *address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+
+	log.Printf("bitx/streamer/server: Listening on %s.", lis.Addr())
+	log.Fatal(gs.Serve(lis))
+}
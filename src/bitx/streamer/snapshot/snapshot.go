@@ -0,0 +1,228 @@
+// Package snapshot turns a series of aggregated order book snapshots into a
+// stream of streamerpb updates.
+//
+// Each price level in a snapshot is represented as a single synthetic order.
+// Between consecutive snapshots, a reduction in volume at or better than the
+// new best price on its side of the book is reported as a trade against that
+// level's order. Any other change replaces the level's order with a new one.
+package snapshot
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/bitx/bitx-go"
+
+	"bitx/streamer/streamerpb"
+)
+
+// Entry is an aggregated price level.
+type Entry struct {
+	PriceE8, VolumeE8 int64
+}
+
+// Book is an aggregated order book snapshot.
+type Book struct {
+	Bids, Asks []Entry
+}
+
+func fromEntries(entries []bitx.OrderBookEntry) []Entry {
+	r := make([]Entry, len(entries))
+	for i, e := range entries {
+		r[i] = Entry{PriceE8: e.Price.E8(), VolumeE8: e.Volume.E8()}
+	}
+	return r
+}
+
+// FromEntries converts order book entries returned by bitx.Client.OrderBook
+// into a Book.
+func FromEntries(bids, asks []bitx.OrderBookEntry) Book {
+	return Book{Bids: fromEntries(bids), Asks: fromEntries(asks)}
+}
+
+// Differ assigns synthetic order IDs to price levels and computes the updates
+// between consecutive snapshots. It is not safe for concurrent use.
+type Differ struct {
+	lastID int64
+	bids   map[int64]*streamerpb.Order
+	asks   map[int64]*streamerpb.Order
+}
+
+// NewDiffer returns a Differ with an empty order book.
+func NewDiffer() *Differ {
+	return &Differ{
+		bids: make(map[int64]*streamerpb.Order),
+		asks: make(map[int64]*streamerpb.Order),
+	}
+}
+
+func aggregate(entries []Entry) map[int64]int64 {
+	levels := make(map[int64]int64)
+	for _, e := range entries {
+		levels[e.PriceE8] += e.VolumeE8
+	}
+	for price, volume := range levels {
+		if volume <= 0 {
+			delete(levels, price)
+		}
+	}
+	return levels
+}
+
+func (d *Differ) newOrder(typ streamerpb.Order_Type, price,
+	volume int64) *streamerpb.Order {
+	d.lastID++
+	return &streamerpb.Order{
+		Type:     typ,
+		OrderId:  d.lastID,
+		PriceE8:  price,
+		VolumeE8: volume,
+	}
+}
+
+// Init resets the differ to the given snapshot and returns it as an order
+// book with sequence 0.
+func (d *Differ) Init(b Book) *streamerpb.OrderBook {
+	d.bids = make(map[int64]*streamerpb.Order)
+	d.asks = make(map[int64]*streamerpb.Order)
+
+	bids, asks := aggregate(b.Bids), aggregate(b.Asks)
+	ob := &streamerpb.OrderBook{}
+	for _, price := range sortedPrices(bids, true) {
+		o := d.newOrder(streamerpb.Order_BID, price, bids[price])
+		d.bids[price] = o
+		ob.Bids = append(ob.Bids, o)
+	}
+	for _, price := range sortedPrices(asks, false) {
+		o := d.newOrder(streamerpb.Order_ASK, price, asks[price])
+		d.asks[price] = o
+		ob.Asks = append(ob.Asks, o)
+	}
+	return copyOrderBook(ob)
+}
+
+// Diff returns the updates which transform the previous snapshot into b.
+// Trades are returned in a single update before any creates or deletes.
+// The returned updates have no sequence numbers.
+func (d *Differ) Diff(b Book) []*streamerpb.Update {
+	var trades []*streamerpb.TradeUpdate
+	var changes []*streamerpb.Update
+
+	sides := []struct {
+		typ    streamerpb.Order_Type
+		orders map[int64]*streamerpb.Order
+		levels map[int64]int64
+		desc   bool
+	}{
+		{streamerpb.Order_BID, d.bids, aggregate(b.Bids), true},
+		{streamerpb.Order_ASK, d.asks, aggregate(b.Asks), false},
+	}
+
+	for _, s := range sides {
+		newPrices := sortedPrices(s.levels, s.desc)
+		oldPrices := make(map[int64]int64, len(s.orders))
+		for price := range s.orders {
+			oldPrices[price] = 0
+		}
+
+		for _, price := range sortedPrices(oldPrices, s.desc) {
+			o := s.orders[price]
+			volume := s.levels[price]
+			switch {
+			case volume == o.VolumeE8:
+				continue
+			case volume < o.VolumeE8 && atOrBetter(price, newPrices, s.desc):
+				traded := o.VolumeE8 - volume
+				trades = append(trades, &streamerpb.TradeUpdate{
+					OrderId:   o.OrderId,
+					BaseE8:    traded,
+					CounterE8: counterE8(traded, price),
+				})
+				o.VolumeE8 = volume
+				if volume == 0 {
+					delete(s.orders, price)
+				}
+			default:
+				u := &streamerpb.Update{
+					DeleteUpdate: &streamerpb.DeleteUpdate{OrderId: o.OrderId},
+				}
+				delete(s.orders, price)
+				if volume > 0 {
+					n := d.newOrder(s.typ, price, volume)
+					s.orders[price] = n
+					u.CreateUpdate = &streamerpb.CreateUpdate{Order: copyOrder(n)}
+				}
+				changes = append(changes, u)
+			}
+		}
+
+		for _, price := range newPrices {
+			if _, ok := s.orders[price]; ok {
+				continue
+			}
+			n := d.newOrder(s.typ, price, s.levels[price])
+			s.orders[price] = n
+			changes = append(changes, &streamerpb.Update{
+				CreateUpdate: &streamerpb.CreateUpdate{Order: copyOrder(n)},
+			})
+		}
+	}
+
+	if len(trades) == 0 {
+		return changes
+	}
+	return append([]*streamerpb.Update{{TradeUpdate: trades}}, changes...)
+}
+
+// atOrBetter returns true if price is at or better than the best of the
+// sorted prices, or if there are no prices left on that side.
+func atOrBetter(price int64, sorted []int64, desc bool) bool {
+	if len(sorted) == 0 {
+		return true
+	}
+	if desc {
+		return price >= sorted[0]
+	}
+	return price <= sorted[0]
+}
+
+// counterE8 returns base * price, both in units of 1e-8.
+func counterE8(base, price int64) int64 {
+	c := new(big.Int).Mul(big.NewInt(base), big.NewInt(price))
+	return c.Quo(c, big.NewInt(1e8)).Int64()
+}
+
+type int64s []int64
+
+func (p int64s) Len() int           { return len(p) }
+func (p int64s) Less(i, j int) bool { return p[i] < p[j] }
+func (p int64s) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+func sortedPrices(levels map[int64]int64, desc bool) []int64 {
+	prices := make(int64s, 0, len(levels))
+	for price := range levels {
+		prices = append(prices, price)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(prices))
+	} else {
+		sort.Sort(prices)
+	}
+	return prices
+}
+
+func copyOrder(o *streamerpb.Order) *streamerpb.Order {
+	c := *o
+	return &c
+}
+
+func copyOrderBook(ob *streamerpb.OrderBook) *streamerpb.OrderBook {
+	c := &streamerpb.OrderBook{Sequence: ob.Sequence}
+	for _, o := range ob.Bids {
+		c.Bids = append(c.Bids, copyOrder(o))
+	}
+	for _, o := range ob.Asks {
+		c.Asks = append(c.Asks, copyOrder(o))
+	}
+	return c
+}
@@ -0,0 +1,226 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bitx/bitx-go"
+
+	"bitx/streamer/streamerpb"
+)
+
+type recordedEntry struct {
+	Price  string `json:"price"`
+	Volume string `json:"volume"`
+}
+
+type recordedBook struct {
+	Bids []recordedEntry `json:"bids"`
+	Asks []recordedEntry `json:"asks"`
+}
+
+func convert(t *testing.T, entries []recordedEntry) []bitx.OrderBookEntry {
+	r := make([]bitx.OrderBookEntry, len(entries))
+	for i, e := range entries {
+		price, err := bitx.ParseAmount(e.Price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		volume, err := bitx.ParseAmount(e.Volume)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r[i] = bitx.OrderBookEntry{Price: price, Volume: volume}
+	}
+	return r
+}
+
+// fakeSnapshotter replays recorded snapshots, repeating the last one.
+type fakeSnapshotter struct {
+	t     *testing.T
+	books []recordedBook
+	next  int
+}
+
+func loadSnapshotter(t *testing.T, name string) *fakeSnapshotter {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var books []recordedBook
+	if err := json.NewDecoder(f).Decode(&books); err != nil {
+		t.Fatal(err)
+	}
+	return &fakeSnapshotter{t: t, books: books}
+}
+
+func (f *fakeSnapshotter) OrderBook(pair string) (
+	bids, asks []bitx.OrderBookEntry, err error) {
+	b := f.books[f.next]
+	if f.next < len(f.books)-1 {
+		f.next++
+	}
+	return convert(f.t, b.Bids), convert(f.t, b.Asks), nil
+}
+
+func (f *fakeSnapshotter) book(i int) Book {
+	b := f.books[i]
+	return FromEntries(convert(f.t, b.Bids), convert(f.t, b.Asks))
+}
+
+// replica applies updates the same way as the streamer client.
+type replica map[int64]*streamerpb.Order
+
+func newReplica(ob *streamerpb.OrderBook) replica {
+	r := make(replica)
+	for _, o := range append(ob.Bids, ob.Asks...) {
+		r[o.OrderId] = copyOrder(o)
+	}
+	return r
+}
+
+func (r replica) apply(t *testing.T, u *streamerpb.Update) {
+	for _, tr := range u.TradeUpdate {
+		o, ok := r[tr.OrderId]
+		if !ok {
+			t.Fatalf("Trade for unknown order %d", tr.OrderId)
+		}
+		o.VolumeE8 -= tr.BaseE8
+		if o.VolumeE8 <= 0 {
+			delete(r, tr.OrderId)
+		}
+	}
+	if u.CreateUpdate != nil {
+		o := u.CreateUpdate.Order
+		r[o.OrderId] = copyOrder(o)
+	}
+	if u.DeleteUpdate != nil {
+		delete(r, u.DeleteUpdate.OrderId)
+	}
+}
+
+func (r replica) levels(typ streamerpb.Order_Type) map[int64]int64 {
+	levels := make(map[int64]int64)
+	for _, o := range r {
+		if o.Type == typ {
+			levels[o.PriceE8] += o.VolumeE8
+		}
+	}
+	return levels
+}
+
+func TestReplayRecordedSnapshots(t *testing.T) {
+	f := loadSnapshotter(t, "testdata/XBTZAR.json")
+	d := NewDiffer()
+	r := newReplica(d.Init(f.book(0)))
+
+	for i := 1; i < len(f.books); i++ {
+		b := f.book(i)
+		for _, u := range d.Diff(b) {
+			r.apply(t, u)
+		}
+		if got, want := r.levels(streamerpb.Order_BID),
+			aggregate(b.Bids); !reflect.DeepEqual(got, want) {
+			t.Errorf("Snapshot %d: expected bids %v, got %v", i, want, got)
+		}
+		if got, want := r.levels(streamerpb.Order_ASK),
+			aggregate(b.Asks); !reflect.DeepEqual(got, want) {
+			t.Errorf("Snapshot %d: expected asks %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestDiffTrades(t *testing.T) {
+	f := loadSnapshotter(t, "testdata/XBTZAR.json")
+	d := NewDiffer()
+	ob := d.Init(f.book(0))
+	if ob.Bids[0].PriceE8 != 500000000000 || ob.Asks[0].PriceE8 != 501000000000 {
+		t.Fatalf("Expected best bid and ask first, got %v", ob)
+	}
+	ask5010, ask5020 := ob.Asks[0].OrderId, ob.Asks[1].OrderId
+
+	// The best ask was taken and the next one partially filled.
+	updates := d.Diff(f.book(1))
+	want := []*streamerpb.Update{{TradeUpdate: []*streamerpb.TradeUpdate{
+		{OrderId: ask5010, BaseE8: 30000000, CounterE8: 150300000000},
+		{OrderId: ask5020, BaseE8: 60000000, CounterE8: 301200000000},
+	}}}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("Expected %v, got %v", want, updates)
+	}
+
+	// The best bid was partially filled, a deeper bid was cancelled, a new
+	// bid was placed and an ask grew.
+	updates = d.Diff(f.book(2))
+	if len(updates) != 4 {
+		t.Fatalf("Expected 4 updates, got %v", updates)
+	}
+	if len(updates[0].TradeUpdate) != 1 ||
+		updates[0].TradeUpdate[0].BaseE8 != 75000000 {
+		t.Errorf("Expected trade of 0.75, got %v", updates[0])
+	}
+	for _, u := range updates[1:] {
+		if u.TradeUpdate != nil {
+			t.Errorf("Expected no more trades, got %v", u)
+		}
+	}
+
+	// Nothing changed.
+	if updates := d.Diff(f.book(3)); len(updates) != 0 {
+		t.Errorf("Expected no updates, got %v", updates)
+	}
+}
+
+func TestNewBestBidIsNotATrade(t *testing.T) {
+	d := NewDiffer()
+	d.Init(Book{Bids: []Entry{{100, 10}}})
+	updates := d.Diff(Book{Bids: []Entry{{101, 1}, {100, 5}}})
+	for _, u := range updates {
+		if len(u.TradeUpdate) > 0 {
+			t.Errorf("Expected no trades, got %v", u)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	f := loadSnapshotter(t, "testdata/XBTZAR.json")
+	s := NewSource(f, "XBTZAR", time.Millisecond)
+	ob, err := s.OrderBook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.OrderBook(); err != ErrStarted {
+		t.Errorf("Expected %v, got %v", ErrStarted, err)
+	}
+	r := newReplica(ob)
+	last := f.book(len(f.books) - 1)
+	for {
+		u := <-s.Updates()
+		r.apply(t, u)
+		if reflect.DeepEqual(r.levels(streamerpb.Order_BID),
+			aggregate(last.Bids)) &&
+			reflect.DeepEqual(r.levels(streamerpb.Order_ASK),
+				aggregate(last.Asks)) {
+			break
+		}
+	}
+	s.Stop()
+	s.Stop()
+	for range s.Updates() {
+	}
+}
+
+func TestSourceStopBeforeStart(t *testing.T) {
+	s := NewSource(loadSnapshotter(t, "testdata/XBTZAR.json"), "XBTZAR",
+		time.Millisecond)
+	s.Stop()
+	for range s.Updates() {
+	}
+	if _, err := s.OrderBook(); err != ErrStopped {
+		t.Errorf("Expected %v, got %v", ErrStopped, err)
+	}
+}
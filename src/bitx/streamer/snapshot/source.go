@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bitx/bitx-go"
+
+	"bitx/streamer/streamerpb"
+)
+
+// Snapshotter returns aggregated order book snapshots. *bitx.Client
+// satisfies this interface.
+type Snapshotter interface {
+	OrderBook(pair string) (bids, asks []bitx.OrderBookEntry, err error)
+}
+
+// ErrStarted indicates that OrderBook has already been called on a Source.
+var ErrStarted = errors.New("Source already started")
+
+// ErrStopped indicates that Stop has already been called on a Source.
+var ErrStopped = errors.New("Source stopped")
+
+// Source polls a Snapshotter and publishes the differences between
+// consecutive snapshots. It implements server.Source.
+type Source struct {
+	snap     Snapshotter
+	pair     string
+	interval time.Duration
+
+	differ  *Differ
+	updates chan *streamerpb.Update
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	quit    chan struct{}
+}
+
+// NewSource returns a source which polls snap for the given pair every
+// interval.
+func NewSource(snap Snapshotter, pair string, interval time.Duration) *Source {
+	return &Source{
+		snap:     snap,
+		pair:     pair,
+		interval: interval,
+		differ:   NewDiffer(),
+		updates:  make(chan *streamerpb.Update),
+		quit:     make(chan struct{}),
+	}
+}
+
+func (s *Source) fetch() (Book, error) {
+	bids, asks, err := s.snap.OrderBook(s.pair)
+	if err != nil {
+		return Book{}, err
+	}
+	return FromEntries(bids, asks), nil
+}
+
+// OrderBook fetches the initial order book and starts polling. It returns
+// ErrStarted if it has already succeeded once, or ErrStopped after Stop.
+func (s *Source) OrderBook() (*streamerpb.OrderBook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return nil, ErrStarted
+	}
+	if s.stopped {
+		return nil, ErrStopped
+	}
+
+	b, err := s.fetch()
+	if err != nil {
+		return nil, err
+	}
+	ob := s.differ.Init(b)
+	s.started = true
+	go s.pollForever()
+	return ob, nil
+}
+
+// Updates returns the channel of order book changes. It is closed after Stop
+// is called.
+func (s *Source) Updates() <-chan *streamerpb.Update {
+	return s.updates
+}
+
+// Stop stops polling. It is safe to call more than once, and before
+// OrderBook.
+func (s *Source) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.quit)
+	if !s.started {
+		// There is no poller to close the channel.
+		close(s.updates)
+	}
+}
+
+func (s *Source) pollForever() {
+	defer close(s.updates)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+
+		b, err := s.fetch()
+		if err != nil {
+			log.Printf("bitx/streamer/snapshot.Source.pollForever: %s: %v",
+				s.pair, err)
+			continue
+		}
+		for _, u := range s.differ.Diff(b) {
+			select {
+			case s.updates <- u:
+			case <-s.quit:
+				return
+			}
+		}
+	}
+}
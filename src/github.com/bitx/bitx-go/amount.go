@@ -0,0 +1,126 @@
+package bitx
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is an exact decimal amount of currency. It is stored as a
+// fixed-point number of units of 1e-8, the same scale as the streamer's _e8
+// fields.
+type Amount int64
+
+// Unit is one whole unit of currency, e.g. 1 XBT or 1 ZAR. To count units,
+// multiply: 5 * Unit is an amount of 5.
+const Unit Amount = 1e8
+
+// decimals is the number of decimal places an Amount can represent.
+const decimals = 8
+
+// ErrInvalidAmount indicates that a string is not a decimal amount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrAmountPrecision indicates that a decimal amount has more decimal places
+// than an Amount can represent.
+var ErrAmountPrecision = errors.New("amount has too many decimal places")
+
+// AmountFromE8 returns the amount for a number of units of 1e-8.
+func AmountFromE8(e8 int64) Amount {
+	return Amount(e8)
+}
+
+// E8 returns the amount as a number of units of 1e-8.
+func (a Amount) E8() int64 {
+	return int64(a)
+}
+
+// ParseAmount parses a decimal string like "-12.345" exactly. It returns
+// ErrAmountPrecision if the string has non-zero digits beyond the eighth
+// decimal place.
+func ParseAmount(s string) (Amount, error) {
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, ErrInvalidAmount
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+	if len(frac) > decimals {
+		if strings.Trim(frac[decimals:], "0") != "" {
+			return 0, ErrAmountPrecision
+		}
+		frac = frac[:decimals]
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+
+	n, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok || n.BitLen() > 63 {
+		return 0, ErrInvalidAmount
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return Amount(n.Int64()), nil
+}
+
+// MustParseAmount is like ParseAmount but panics if the string cannot be
+// parsed. It simplifies safe initialisation of amounts.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic("bitx: MustParseAmount(" + strconv.Quote(s) + "): " +
+			err.Error())
+	}
+	return a
+}
+
+// String formats the amount as a decimal without loss of precision or
+// trailing zeros, e.g. "0.005" or "5000".
+func (a Amount) String() string {
+	s := strconv.FormatUint(uint64(a), 10)
+	sign := ""
+	if a < 0 {
+		sign = "-"
+		s = strconv.FormatUint(uint64(-a), 10)
+	}
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// Float64 returns the nearest float64 to the amount. It should only be used
+// for display or approximate calculations.
+func (a Amount) Float64() float64 {
+	return float64(a) / float64(Unit)
+}
+
+// Mul returns a * b, rounded towards zero, e.g. to find the counter amount
+// of a base volume at a price.
+func (a Amount) Mul(b Amount) Amount {
+	p := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(b)))
+	return Amount(p.Quo(p, big.NewInt(int64(Unit))).Int64())
+}
+
+// Div returns a / b, rounded towards zero, e.g. to find the base volume which
+// a counter amount buys at a price. It panics if b is zero.
+func (a Amount) Div(b Amount) Amount {
+	q := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(Unit)))
+	return Amount(q.Quo(q, big.NewInt(int64(b))).Int64())
+}
@@ -0,0 +1,71 @@
+package bitx
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		{"0", 0, nil},
+		{"1", Unit, nil},
+		{"5000.00", 5000 * Unit, nil},
+		{"0.005", 500000, nil},
+		{"0.00000001", 1, nil},
+		{"-12.5", -1250000000, nil},
+		{".5", Unit / 2, nil},
+		{"1.100000000", 110000000, nil},
+		{"0.000000001", 0, ErrAmountPrecision},
+		{"", 0, ErrInvalidAmount},
+		{".", 0, ErrInvalidAmount},
+		{"1e5", 0, ErrInvalidAmount},
+		{"1.2.3", 0, ErrInvalidAmount},
+		{"99999999999999999999", 0, ErrInvalidAmount},
+	}
+	for _, test := range tests {
+		got, err := ParseAmount(test.in)
+		if err != test.err || got != test.want {
+			t.Errorf("ParseAmount(%q): expected %d, %v, got %d, %v",
+				test.in, test.want, test.err, got, err)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := map[Amount]string{
+		0:                    "0",
+		Unit:                 "1",
+		500000:               "0.005",
+		1:                    "0.00000001",
+		-1250000000:          "-12.5",
+		123456789012345678:   "1234567890.12345678",
+		AmountFromE8(5e11):   "5000",
+		MustParseAmount("3"): "3",
+	}
+	for a, want := range tests {
+		if got := a.String(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+		if back := MustParseAmount(want); back != a {
+			t.Errorf("Expected %q to round trip, got %d", want, back)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	volume := MustParseAmount("0.005")
+	price := MustParseAmount("5000.01")
+	if got := volume.Mul(price); got != MustParseAmount("25.00005") {
+		t.Errorf("Expected 25.00005, got %s", got)
+	}
+	if got := MustParseAmount("25.00005").Div(price); got != volume {
+		t.Errorf("Expected 0.005, got %s", got)
+	}
+	if got := (Unit / 3).Mul(3 * Unit); got != MustParseAmount("0.99999999") {
+		t.Errorf("Expected rounding towards zero, got %s", got)
+	}
+	if volume.E8() != 500000 || volume.Float64() != 0.005 {
+		t.Errorf("Unexpected conversions for %s", volume)
+	}
+}
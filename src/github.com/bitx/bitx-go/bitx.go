@@ -7,121 +7,272 @@ import (
 	_ "crypto/sha512"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/context/ctxhttp"
 )
 
-const userAgent = "bitx-go/0.0.3"
+const userAgent = "bitx-go/0.0.4"
+
+// maxConcurrentRequests is the number of requests sent at once by calls which
+// make a request per item, like Snapshot and CancelAll.
+const maxConcurrentRequests = 6
 
 var base = url.URL{Scheme: "https", Host: "api.mybitx.com"}
 
 type Client struct {
 	api_key_id, api_key_secret string
+	base                       url.URL
+	userAgent                  string
+
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+
+	limiter    *Limiter
+	maxRetries int
+	retryBase  time.Duration
+	retryPost  bool
+
+	lenient bool
+	rawJSON bool
+
+	interceptors []Interceptor
+
+	validateOrders bool
+	marketsMu      sync.Mutex
+	markets        map[string]Market
+}
+
+// An Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the URL of the API, e.g. to point the client at a staging
+// host or a local fake exchange.
+func WithBaseURL(u *url.URL) Option {
+	return func(c *Client) {
+		c.base = *u
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make requests. The client is
+// copied, so later changes to it have no effect.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTransport sets the transport used to make requests, overriding that of
+// any client set with WithHTTPClient.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithTimeout sets a time limit for each request, overriding that of any
+// client set with WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with each request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
 }
 
 // Pass an empty string for the api_key_id if you will only access the public
 // API.
-func NewClient(api_key_id, api_key_secret string) *Client {
-	return &Client{api_key_id, api_key_secret}
+func NewClient(api_key_id, api_key_secret string, opts ...Option) *Client {
+	c := &Client{api_key_id: api_key_id, api_key_secret: api_key_secret,
+		base: base, userAgent: userAgent, maxRetries: defaultMaxRetries}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	var hc http.Client
+	if c.httpClient != nil {
+		hc = *c.httpClient
+	}
+	if c.transport != nil {
+		hc.Transport = c.transport
+	}
+	if c.timeout > 0 {
+		hc.Timeout = c.timeout
+	}
+	c.httpClient = &hc
+
+	return c
 }
 
-func (c *Client) call(method, path string, params url.Values,
-	result interface{}) error {
-	u := base
-	u.Path = path
+// do sends a single request. transient is true if the request failed before
+// a response was received.
+func (c *Client) do(ctx context.Context, apiReq *Request) (
+	transient bool, err error) {
+	method, result := apiReq.Method, apiReq.Result
+	u := c.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + apiReq.Path
 
 	var body *bytes.Reader
-	if method == "GET" {
-		u.RawQuery = params.Encode()
+	switch method {
+	case "GET", "DELETE":
+		u.RawQuery = apiReq.Params.Encode()
 		body = bytes.NewReader(nil)
-	} else if method == "POST" {
-		body = bytes.NewReader([]byte(params.Encode()))
-	} else {
-		return errors.New("Unsupported method")
+	case "POST", "PUT":
+		body = bytes.NewReader([]byte(apiReq.Params.Encode()))
+	default:
+		return false, errors.New("Unsupported method")
 	}
 
 	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if c.api_key_id != "" {
 		req.SetBasicAuth(c.api_key_id, c.api_key_secret)
 	}
-	req.Header.Add("User-Agent", userAgent)
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	r, err := (&http.Client{}).Do(req)
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, v := range apiReq.Header {
+		req.Header[k] = v
+	}
+	r, err := ctxhttp.Do(ctx, c.httpClient, req)
 	if err != nil {
-		return err
+		return ctx.Err() == nil, err
 	}
 	defer r.Body.Close()
+	// Drain the body so that the connection can be reused.
+	defer io.Copy(ioutil.Discard, r.Body)
 
 	if r.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(r.Body)
-		return errors.New(fmt.Sprintf(
-			"BitX error %d: %s: %s",
-			r.StatusCode, r.Status, string(body)))
+		return false, newAPIError(method, r)
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(result); err != nil {
-		return err
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return false, err
 	}
 
-	return nil
+	if e, ok := result.(errorResponse); ok {
+		resp := e.response()
+		if c.rawJSON {
+			resp.raw = data
+		}
+		if resp.Error != "" {
+			return false, &APIError{
+				StatusCode: r.StatusCode,
+				Code:       resp.ErrorCode,
+				Message:    resp.Error,
+			}
+		}
+	}
+
+	return false, nil
 }
 
 type ticker struct {
-	Error     string `json:"error"`
+	apiResponse
 	Timestamp int64  `json:"timestamp"`
+	Pair      string `json:"pair"`
 	Bid       string `json:"bid"`
 	Ask       string `json:"ask"`
 	Last      string `json:"last_trade"`
 	Volume24H string `json:"rolling_24_hour_volume"`
 }
 
+type tickers struct {
+	apiResponse
+	Tickers []ticker `json:"tickers"`
+}
+
 type Ticker struct {
 	Timestamp                 time.Time
-	Bid, Ask, Last, Volume24H float64
+	Pair                      string
+	Bid, Ask, Last, Volume24H Amount
+	Raw                       json.RawMessage // See WithRawJSON.
+}
+
+// parseTicker converts a ticker. prefix is the path of the ticker in the
+// response, e.g. "tickers[2].", for error messages.
+func parseTicker(p *parser, prefix string, r ticker) Ticker {
+	return Ticker{
+		Timestamp: millis(r.Timestamp),
+		Pair:      r.Pair,
+		Bid:       p.amount(prefix+"bid", r.Bid),
+		Ask:       p.amount(prefix+"ask", r.Ask),
+		Last:      p.amount(prefix+"last_trade", r.Last),
+		Volume24H: p.amount(prefix+"rolling_24_hour_volume", r.Volume24H),
+	}
 }
 
 // Returns the latest ticker indicators for the given currency pair..
 func (c *Client) Ticker(pair string) (Ticker, error) {
+	return c.TickerContext(context.Background(), pair)
+}
+
+// TickerContext is like Ticker but takes a context.
+func (c *Client) TickerContext(ctx context.Context, pair string) (
+	Ticker, error) {
 	var r ticker
-	err := c.call("GET", "/api/1/ticker", url.Values{"pair": {pair}}, &r)
+	err := c.call(ctx, "GET", "/api/1/ticker", url.Values{"pair": {pair}}, &r)
 	if err != nil {
 		return Ticker{}, err
 	}
-	if r.Error != "" {
-		return Ticker{}, errors.New("BitX error: " + r.Error)
+
+	p := c.parser()
+	t := parseTicker(p, "", r)
+	t.Raw = r.raw
+	if p.err != nil {
+		return Ticker{}, p.err
+	}
+	if t.Pair == "" {
+		t.Pair = pair
 	}
 
-	t := time.Unix(r.Timestamp/1000, 0)
+	return t, nil
+}
 
-	bid, err := strconv.ParseFloat(r.Bid, 64)
-	if err != nil {
-		return Ticker{}, err
-	}
+// Returns the latest ticker indicators for all currency pairs in one
+// request.
+func (c *Client) Tickers() ([]Ticker, error) {
+	return c.TickersContext(context.Background())
+}
 
-	ask, err := strconv.ParseFloat(r.Ask, 64)
+// TickersContext is like Tickers but takes a context.
+func (c *Client) TickersContext(ctx context.Context) ([]Ticker, error) {
+	var r tickers
+	err := c.call(ctx, "GET", "/api/1/tickers", nil, &r)
 	if err != nil {
-		return Ticker{}, err
+		return nil, err
 	}
 
-	last, err := strconv.ParseFloat(r.Last, 64)
-	if err != nil {
-		return Ticker{}, err
+	p := c.parser()
+	raws := r.rawList("tickers")
+	ts := make([]Ticker, len(r.Tickers))
+	for i, t := range r.Tickers {
+		ts[i] = parseTicker(p, index("tickers", i)+".", t)
+		ts[i].Raw = rawAt(raws, i)
 	}
-
-	volume24h, err := strconv.ParseFloat(r.Volume24H, 64)
-	if err != nil {
-		return Ticker{}, err
+	if p.err != nil {
+		return nil, p.err
 	}
-
-	return Ticker{t, bid, ask, last, volume24h}, nil
+	return ts, nil
 }
 
 type orderbookEntry struct {
@@ -130,22 +281,23 @@ type orderbookEntry struct {
 }
 
 type orderbook struct {
-	Error string           `json:"error"`
-	Asks  []orderbookEntry `json:"asks"`
-	Bids  []orderbookEntry `json:"bids"`
+	apiResponse
+	Asks []orderbookEntry `json:"asks"`
+	Bids []orderbookEntry `json:"bids"`
 }
 
 type OrderBookEntry struct {
-	Price, Volume float64
+	Price, Volume Amount
+	Raw           json.RawMessage // See WithRawJSON.
 }
 
-func convert(entries []orderbookEntry) (r []OrderBookEntry) {
+func convert(p *parser, field string, entries []orderbookEntry,
+	raws []json.RawMessage) (r []OrderBookEntry) {
 	r = make([]OrderBookEntry, len(entries))
 	for i, e := range entries {
-		price, _ := strconv.ParseFloat(e.Price, 64)
-		volume, _ := strconv.ParseFloat(e.Volume, 64)
-		r[i].Price = price
-		r[i].Volume = volume
+		r[i].Price = p.amount(index(field, i)+".price", e.Price)
+		r[i].Volume = p.amount(index(field, i)+".volume", e.Volume)
+		r[i].Raw = rawAt(raws, i)
 	}
 	return r
 }
@@ -154,17 +306,26 @@ func convert(entries []orderbookEntry) (r []OrderBookEntry) {
 // pair.
 func (c *Client) OrderBook(pair string) (
 	bids, asks []OrderBookEntry, err error) {
+	return c.OrderBookContext(context.Background(), pair)
+}
+
+// OrderBookContext is like OrderBook but takes a context.
+func (c *Client) OrderBookContext(ctx context.Context, pair string) (
+	bids, asks []OrderBookEntry, err error) {
 
 	var r orderbook
-	err = c.call("GET", "/api/1/orderbook", url.Values{"pair": {pair}}, &r)
+	err = c.call(ctx, "GET", "/api/1/orderbook", url.Values{"pair": {pair}}, &r)
 	if err != nil {
 		return nil, nil, err
 	}
-	if r.Error != "" {
-		return nil, nil, errors.New("BitX error: " + r.Error)
-	}
 
-	return convert(r.Bids), convert(r.Asks), nil
+	p := c.parser()
+	bids = convert(p, "bids", r.Bids, r.rawList("bids"))
+	asks = convert(p, "asks", r.Asks, r.rawList("asks"))
+	if p.err != nil {
+		return nil, nil, p.err
+	}
+	return bids, asks, nil
 }
 
 type trade struct {
@@ -174,40 +335,49 @@ type trade struct {
 }
 
 type trades struct {
-	Error  string  `json:"error"`
+	apiResponse
 	Trades []trade `json:"trades"`
 }
 
 type Trade struct {
 	Timestamp     time.Time
-	Price, Volume float64
+	Price, Volume Amount
+	Raw           json.RawMessage // See WithRawJSON.
 }
 
 // Returns a list of the most recent trades for the given currency pair.
 func (c *Client) Trades(pair string) ([]Trade, error) {
+	return c.TradesContext(context.Background(), pair)
+}
+
+// TradesContext is like Trades but takes a context.
+func (c *Client) TradesContext(ctx context.Context, pair string) (
+	[]Trade, error) {
 	var r trades
-	err := c.call("GET", "/api/1/trades", url.Values{"pair": {pair}}, &r)
+	err := c.call(ctx, "GET", "/api/1/trades", url.Values{"pair": {pair}}, &r)
 	if err != nil {
 		return nil, err
 	}
-	if r.Error != "" {
-		return nil, errors.New("BitX error: " + r.Error)
-	}
 
+	p := c.parser()
+	raws := r.rawList("trades")
 	tr := make([]Trade, len(r.Trades))
 	for i, t := range r.Trades {
-		tr[i].Timestamp = time.Unix(t.Timestamp/1000, 0)
-		price, _ := strconv.ParseFloat(t.Price, 64)
-		volume, _ := strconv.ParseFloat(t.Volume, 64)
-		tr[i].Price = price
-		tr[i].Volume = volume
+		field := index("trades", i)
+		tr[i].Timestamp = millis(t.Timestamp)
+		tr[i].Price = p.amount(field+".price", t.Price)
+		tr[i].Volume = p.amount(field+".volume", t.Volume)
+		tr[i].Raw = rawAt(raws, i)
+	}
+	if p.err != nil {
+		return nil, p.err
 	}
 	return tr, nil
 }
 
 type postorder struct {
+	apiResponse
 	OrderId string `json:"order_id"`
-	Error   string `json:"error"`
 }
 
 type OrderType string
@@ -215,29 +385,81 @@ type OrderType string
 const BID = OrderType("BID")
 const ASK = OrderType("ASK")
 
+// Market order types.
+const BUY = OrderType("BUY")
+const SELL = OrderType("SELL")
+
 // Create a new trade order.
 func (c *Client) PostOrder(pair string, order_type OrderType,
-	volume, price float64) (string, error) {
+	volume, price Amount) (string, error) {
+	return c.PostOrderContext(context.Background(), pair, order_type,
+		volume, price)
+}
+
+// PostOrderContext is like PostOrder but takes a context.
+func (c *Client) PostOrderContext(ctx context.Context, pair string,
+	order_type OrderType, volume, price Amount) (string, error) {
+	if c.validateOrders {
+		m, err := c.cachedMarket(ctx, pair)
+		if err != nil {
+			return "", err
+		}
+		volume, price, err = m.ValidateOrder(order_type, volume, price)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	form := make(url.Values)
-	form.Add("volume", fmt.Sprintf("%f", volume))
-	form.Add("price", fmt.Sprintf("%f", price))
+	form.Add("volume", volume.String())
+	form.Add("price", price.String())
 	form.Add("pair", pair)
 	form.Add("type", string(order_type))
 
 	var r postorder
-	err := c.call("POST", "/api/1/postorder", form, &r)
+	err := c.call(ctx, "POST", "/api/1/postorder", form, &r)
 	if err != nil {
 		return "", err
 	}
-	if r.Error != "" {
-		return "", errors.New("BitX error: " + r.Error)
+
+	return r.OrderId, nil
+}
+
+// Create a new market order. A BUY order spends volume of the counter
+// currency and a SELL order sells volume of the base currency, at the best
+// prices available. The fill details can be retrieved with GetOrder.
+func (c *Client) PostMarketOrder(pair string, order_type OrderType,
+	volume Amount) (string, error) {
+	return c.PostMarketOrderContext(context.Background(), pair, order_type,
+		volume)
+}
+
+// PostMarketOrderContext is like PostMarketOrder but takes a context.
+func (c *Client) PostMarketOrderContext(ctx context.Context, pair string,
+	order_type OrderType, volume Amount) (string, error) {
+	form := make(url.Values)
+	switch order_type {
+	case BUY:
+		form.Add("counter_volume", volume.String())
+	case SELL:
+		form.Add("base_volume", volume.String())
+	default:
+		return "", errors.New("Market order type must be BUY or SELL")
+	}
+	form.Add("pair", pair)
+	form.Add("type", string(order_type))
+
+	var r postorder
+	err := c.call(ctx, "POST", "/api/1/marketorder", form, &r)
+	if err != nil {
+		return "", err
 	}
 
 	return r.OrderId, nil
 }
 
 type order struct {
-	Error             string `json:"error"`
+	apiResponse
 	OrderId           string `json:"order_id"`
 	CreationTimestamp int64  `json:"creation_timestamp"`
 	Type              string `json:"type"`
@@ -248,66 +470,73 @@ type order struct {
 	Counter           string `json:"counter"`
 	FeeBase           string `json:"fee_base"`
 	FeeCounter        string `json:"fee_counter"`
+	Pair              string `json:"pair"`
+	// Completion and expiration timestamps are zero if not applicable.
+	CompletedTimestamp  int64 `json:"completed_timestamp"`
+	ExpirationTimestamp int64 `json:"expiration_timestamp"`
 }
 
 type orders struct {
-	Error  string  `json:"error"`
+	apiResponse
 	Orders []order `json:"orders"`
 }
 
+// OrderState is the state of an order as reported by the API. States not
+// listed below are kept verbatim, so new states can still be inspected.
 type OrderState string
 
+// Awaiting orders have been accepted but not yet placed in the order book.
+const Awaiting = OrderState("AWAITING")
 const Pending = OrderState("PENDING")
 const Complete = OrderState("COMPLETE")
 
 type Order struct {
 	Id                  string
+	Pair                string
 	CreatedAt           time.Time
 	Type                OrderType
 	State               OrderState
-	LimitPrice          float64
-	LimitVolume         float64
-	Base, Counter       float64
-	FeeBase, FeeCounter float64
+	LimitPrice          Amount
+	LimitVolume         Amount
+	Base, Counter       Amount
+	FeeBase, FeeCounter Amount
+	// CompletedAt is zero until the order is complete.
+	CompletedAt time.Time
+	// ExpiresAt is zero if the order does not expire.
+	ExpiresAt time.Time
+	Raw       json.RawMessage // See WithRawJSON.
 }
 
-func atofloat64(s string) float64 {
-	f, _ := strconv.ParseFloat(s, 64)
-	return f
-}
-
-func parseOrder(bo order) Order {
+// parseOrder converts an order. prefix is the path of the order in the
+// response, e.g. "orders[2].", for error messages.
+func parseOrder(p *parser, prefix string, bo order) Order {
 	var o Order
 	o.Id = bo.OrderId
+	o.Pair = bo.Pair
 	o.Type = OrderType(bo.Type)
 	o.State = OrderState(bo.State)
-	o.CreatedAt = time.Unix(bo.CreationTimestamp/1000, 0)
-	o.LimitPrice = atofloat64(bo.LimitPrice)
-	o.LimitVolume = atofloat64(bo.LimitVolume)
-	o.Base = atofloat64(bo.Base)
-	o.Counter = atofloat64(bo.Counter)
-	o.FeeBase = atofloat64(bo.FeeBase)
-	o.FeeCounter = atofloat64(bo.FeeCounter)
+	o.CreatedAt = millis(bo.CreationTimestamp)
+	o.CompletedAt = optionalMillis(bo.CompletedTimestamp)
+	o.ExpiresAt = optionalMillis(bo.ExpirationTimestamp)
+	o.LimitPrice = p.amount(prefix+"limit_price", bo.LimitPrice)
+	o.LimitVolume = p.amount(prefix+"limit_volume", bo.LimitVolume)
+	o.Base = p.amount(prefix+"base", bo.Base)
+	o.Counter = p.amount(prefix+"counter", bo.Counter)
+	o.FeeBase = p.amount(prefix+"fee_base", bo.FeeBase)
+	o.FeeCounter = p.amount(prefix+"fee_counter", bo.FeeCounter)
 	return o
 }
 
 // Returns a list of the most recently placed orders.
 // The list is truncated after 100 items.
 func (c *Client) ListOrders(pair string) ([]Order, error) {
-	var r orders
-	err := c.call("GET", "/api/1/listorders", url.Values{"pair": {pair}}, &r)
-	if err != nil {
-		return nil, err
-	}
-	if r.Error != "" {
-		return nil, errors.New("BitX error: " + r.Error)
-	}
+	return c.ListOrdersContext(context.Background(), pair)
+}
 
-	orders := make([]Order, len(r.Orders))
-	for i, bo := range r.Orders {
-		orders[i] = parseOrder(bo)
-	}
-	return orders, nil
+// ListOrdersContext is like ListOrders but takes a context.
+func (c *Client) ListOrdersContext(ctx context.Context, pair string) (
+	[]Order, error) {
+	return c.ListOrdersWithOptionsContext(ctx, ListOrdersOptions{Pair: pair})
 }
 
 var pathIDRegex = regexp.MustCompile("^[[:alnum:]]+$")
@@ -321,73 +550,157 @@ func isValidPathID(id string) bool {
 
 // Get an order by its id.
 func (c *Client) GetOrder(id string) (*Order, error) {
+	return c.GetOrderContext(context.Background(), id)
+}
+
+// GetOrderContext is like GetOrder but takes a context.
+func (c *Client) GetOrderContext(ctx context.Context, id string) (
+	*Order, error) {
 	if !isValidPathID(id) {
 		return nil, errors.New("invalid order id")
 	}
 	var bo order
-	err := c.call("GET", "/api/1/orders/"+id, nil, &bo)
+	err := c.call(ctx, "GET", "/api/1/orders/"+id, nil, &bo)
 	if err != nil {
 		return nil, err
 	}
-	if bo.Error != "" {
-		return nil, errors.New("BitX error: " + bo.Error)
+	p := c.parser()
+	o := parseOrder(p, "", bo)
+	o.Raw = bo.raw
+	if p.err != nil {
+		return nil, p.err
 	}
-	o := parseOrder(bo)
 	return &o, nil
 }
 
 type stoporder struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
+	apiResponse
+	Success bool `json:"success"`
 }
 
-// Request to stop an order.
+// Request to stop an order. It returns ErrNotStopped if the API reports that
+// the order wasn't stopped.
 func (c *Client) StopOrder(id string) error {
+	return c.StopOrderContext(context.Background(), id)
+}
+
+// StopOrderContext is like StopOrder but takes a context.
+func (c *Client) StopOrderContext(ctx context.Context, id string) error {
 	form := make(url.Values)
 	form.Add("order_id", id)
 	var r stoporder
-	err := c.call("POST", "/api/1/stoporder", form, &r)
+	err := c.call(ctx, "POST", "/api/1/stoporder", form, &r)
 	if err != nil {
 		return err
 	}
-	if r.Error != "" {
-		return errors.New("BitX error: " + r.Error)
+	if !r.Success {
+		return ErrNotStopped
 	}
 	return nil
 }
 
 type balance struct {
-	Asset    string `json:"asset"`
-	Balance  string `json:"balance"`
-	Reserved string `json:"reserved"`
+	AccountId   string `json:"account_id"`
+	Asset       string `json:"asset"`
+	Balance     string `json:"balance"`
+	Reserved    string `json:"reserved"`
+	Unconfirmed string `json:"unconfirmed"`
 }
 
 type balances struct {
-	Error   string    `json:"error"`
+	apiResponse
 	Balance []balance `json:"balance"`
 }
 
+// Balance is the balance of one of the accounts for the API key.
+type Balance struct {
+	AccountId string
+	Asset     string
+	// Balance includes reserved funds. The available balance is Balance
+	// minus Reserved.
+	Balance, Reserved Amount
+	// Unconfirmed is the amount of incoming funds awaiting confirmation.
+	Unconfirmed Amount
+	Raw         json.RawMessage // See WithRawJSON.
+}
+
 // Returns the trading account balance and reserved funds.
 func (c *Client) Balance(asset string) (
-	balance float64, reserved float64, err error) {
-	var r balances
-	err = c.call("GET", "/api/1/balance", url.Values{"asset": {asset}}, &r)
+	balance Amount, reserved Amount, err error) {
+	return c.BalanceContext(context.Background(), asset)
+}
+
+// BalanceContext is like Balance but takes a context.
+func (c *Client) BalanceContext(ctx context.Context, asset string) (
+	balance Amount, reserved Amount, err error) {
+	bl, err := c.BalancesContext(ctx, asset)
 	if err != nil {
 		return 0, 0, err
 	}
-	if r.Error != "" {
-		return 0, 0, errors.New("BitX error: " + r.Error)
-	}
-	if len(r.Balance) == 0 {
+	if len(bl) == 0 {
 		return 0, 0, errors.New("Balance not returned")
 	}
+	return bl[0].Balance, bl[0].Reserved, nil
+}
+
+// Returns the balances of all accounts for the API key. If any assets are
+// given, only the balances of accounts for those assets are returned.
+func (c *Client) Balances(assets ...string) ([]Balance, error) {
+	return c.BalancesContext(context.Background(), assets...)
+}
+
+// BalancesContext is like Balances but takes a context.
+func (c *Client) BalancesContext(ctx context.Context, assets ...string) (
+	[]Balance, error) {
+	params := url.Values{}
+	if len(assets) == 1 && assets[0] != "" {
+		params.Set("asset", assets[0])
+	}
+	var r balances
+	err := c.call(ctx, "GET", "/api/1/balance", params, &r)
+	if err != nil {
+		return nil, err
+	}
 
-	balance = atofloat64(r.Balance[0].Balance)
-	reserved = atofloat64(r.Balance[0].Reserved)
-	return balance, reserved, nil
+	want := make(map[string]bool, len(assets))
+	for _, asset := range assets {
+		// An empty asset, as in Balance(""), selects no particular asset.
+		if asset != "" {
+			want[asset] = true
+		}
+	}
+
+	p := c.parser()
+	raws := r.rawList("balance")
+	bl := make([]Balance, 0, len(r.Balance))
+	for i, b := range r.Balance {
+		if len(want) > 0 && !want[b.Asset] {
+			continue
+		}
+		field := index("balance", i)
+		bl = append(bl, Balance{
+			AccountId:   b.AccountId,
+			Asset:       b.Asset,
+			Balance:     p.amount(field+".balance", b.Balance),
+			Reserved:    p.amount(field+".reserved", b.Reserved),
+			Unconfirmed: p.amount(field+".unconfirmed", b.Unconfirmed),
+			Raw:         rawAt(raws, i),
+		})
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return bl, nil
 }
 
 func (c *Client) Send(amount, currency, address, desc, message string) error {
+	return c.SendContext(context.Background(), amount, currency, address,
+		desc, message)
+}
+
+// SendContext is like Send but takes a context.
+func (c *Client) SendContext(ctx context.Context, amount, currency, address,
+	desc, message string) error {
 	form := make(url.Values)
 	form.Add("amount", amount)
 	form.Add("currency", currency)
@@ -396,13 +709,10 @@ func (c *Client) Send(amount, currency, address, desc, message string) error {
 	form.Add("message", message)
 
 	var r stoporder
-	err := c.call("POST", "/api/1/send", form, &r)
+	err := c.call(ctx, "POST", "/api/1/send", form, &r)
 	if err != nil {
 		return err
 	}
-	if r.Error != "" {
-		return errors.New("BitX error: " + r.Error)
-	}
 
 	return nil
 }
@@ -1,6 +1,14 @@
 package bitx
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
 
 func TestExample(t *testing.T) {
 	c := NewClient("test", "test")
@@ -8,3 +16,171 @@ func TestExample(t *testing.T) {
 		t.Errorf("Expected valid client, got: %v", c)
 	}
 }
+
+func newTestServer(t *testing.T, h http.HandlerFunc) (*httptest.Server, Option) {
+	s := httptest.NewServer(h)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, WithBaseURL(u)
+}
+
+func TestOptions(t *testing.T) {
+	var gotPath, gotUA string
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUA = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"bids":[],"asks":[]}`))
+	})
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL + "/prefix/")
+	c := NewClient("", "", withBase, WithBaseURL(u), WithUserAgent("test/1.0"))
+	if _, _, err := c.OrderBook("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/prefix/api/1/orderbook" {
+		t.Errorf("Expected path /prefix/api/1/orderbook, got %s", gotPath)
+	}
+	if gotUA != "test/1.0" {
+		t.Errorf("Expected user agent test/1.0, got %s", gotUA)
+	}
+}
+
+type countingTransport struct {
+	n int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTransportAndTimeout(t *testing.T) {
+	block := make(chan struct{})
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	})
+	defer s.Close()
+	defer close(block)
+
+	hc := &http.Client{Timeout: time.Hour}
+	rt := &countingTransport{}
+	c := NewClient("", "", withBase, WithHTTPClient(hc), WithTransport(rt),
+		WithTimeout(10*time.Millisecond), WithRetry(0, 0))
+	if _, err := c.Ticker("XBTZAR"); err == nil {
+		t.Errorf("Expected timeout error")
+	}
+	if rt.n != 1 {
+		t.Errorf("Expected one request through the transport, got %d", rt.n)
+	}
+	if hc.Timeout != time.Hour {
+		t.Errorf("Expected the supplied client to be unchanged")
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	block := make(chan struct{})
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	})
+	defer s.Close()
+	defer close(block)
+
+	c := NewClient("", "", withBase)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, _, err := c.OrderBookContext(ctx, "XBTZAR"); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		body   string
+		want   APIError
+	}{
+		{"GET", 429, `{"error":"Too many requests","error_code":"ErrTooManyRequests"}`,
+			APIError{StatusCode: 429, Code: "ErrTooManyRequests", Message: "Too many requests",
+				Retryable: true}},
+		{"POST", 429, ``,
+			APIError{StatusCode: 429, Code: "", Message: "429 Too Many Requests",
+				Retryable: true}},
+		{"GET", 502, `Bad gateway`,
+			APIError{StatusCode: 502, Code: "", Message: "502 Bad Gateway: Bad gateway",
+				Retryable: true}},
+		{"POST", 500, `{"error":"Internal error"}`,
+			APIError{StatusCode: 500, Code: "", Message: "Internal error",
+				Retryable: false}},
+		{"POST", 401, `{"error":"Unauthorised","error_code":"ErrUnauthorised"}`,
+			APIError{StatusCode: 401, Code: "ErrUnauthorised", Message: "Unauthorised",
+				Retryable: false}},
+		{"POST", 200, `{"error":"Insufficient balance","error_code":"ErrInsufficientBalance"}`,
+			APIError{StatusCode: 200, Code: "ErrInsufficientBalance", Message: "Insufficient balance",
+				Retryable: false}},
+	}
+
+	for _, test := range tests {
+		s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		})
+		c := NewClient("", "", withBase, WithRetry(0, 0))
+		var err error
+		if test.method == "GET" {
+			_, err = c.Ticker("XBTZAR")
+		} else {
+			err = c.StopOrder("BXID1")
+		}
+		s.Close()
+
+		e, ok := err.(*APIError)
+		if !ok {
+			t.Errorf("%s %d: expected *APIError, got %v", test.method,
+				test.status, err)
+			continue
+		}
+		if *e != test.want {
+			t.Errorf("%s %d: expected %+v, got %+v", test.method, test.status,
+				test.want, *e)
+		}
+		if IsRetryable(err) != test.want.Retryable {
+			t.Errorf("%s %d: expected IsRetryable %t", test.method,
+				test.status, test.want.Retryable)
+		}
+	}
+}
+
+func TestBalanceEmptyAsset(t *testing.T) {
+	var query string
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"balance":[{"account_id":"1","asset":"XBT",` +
+			`"balance":"2","reserved":"0.5","unconfirmed":"0"}]}`))
+	})
+	defer s.Close()
+
+	b, reserved, err := NewClient("", "", withBase).Balance("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != 2*Unit || reserved != Unit/2 || query != "" {
+		t.Errorf("Expected default balance without asset filter, got %s %s "+
+			"(query %q)", b, reserved, query)
+	}
+}
+
+func TestStopOrderNotStopped(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false}`))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase)
+	if err := c.StopOrder("BXID1"); err != ErrNotStopped {
+		t.Errorf("Expected %v, got %v", ErrNotStopped, err)
+	}
+}
@@ -0,0 +1,243 @@
+package bitxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Interaction is a request and the response it received.
+type Interaction struct {
+	Method string `json:"method"`
+	// URI is the path and query of the request, without the host, so that
+	// a cassette can be replayed against any base URL.
+	URI  string `json:"uri"`
+	Body string `json:"body,omitempty"`
+
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	ResponseBody string      `json:"response_body"`
+}
+
+// Cassette is a sequence of recorded interactions. It holds no credentials:
+// request headers and cookies set by responses aren't recorded, and the API
+// key is replaced with REDACTED wherever else it appears.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette saved with Cassette.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("bitxtest: %s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to a file as indented JSON.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// readBody reads and replaces the body of a request so that it can still be
+// sent.
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return string(b), nil
+}
+
+// Recorder is a transport which records the requests it sends and their
+// responses. Use it with bitx.WithTransport, then save its cassette:
+//
+//	rec := bitxtest.NewRecorder(nil)
+//	c := bitx.NewClient(id, secret, bitx.WithTransport(rec))
+//	... use c ...
+//	err := rec.Cassette().Save("testdata/ticker.json")
+type Recorder struct {
+	rt http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a recorder which sends requests with rt, or with
+// http.DefaultTransport if rt is nil.
+func NewRecorder(rt http.RoundTripper) *Recorder {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &Recorder{rt: rt}
+}
+
+// RoundTrip sends a request and records it with its response. Requests
+// which fail without a response aren't recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	var secrets []string
+	if id, secret, ok := req.BasicAuth(); ok {
+		for _, k := range []string{id, secret} {
+			if k != "" {
+				secrets = append(secrets, k, "REDACTED")
+			}
+		}
+	}
+	redact := strings.NewReplacer(secrets...).Replace
+	header := make(http.Header)
+	for k, v := range resp.Header {
+		if k != "Set-Cookie" {
+			header[k] = v
+		}
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:       req.Method,
+		URI:          redact(req.URL.RequestURI()),
+		Body:         redact(body),
+		Status:       resp.StatusCode,
+		Header:       header,
+		ResponseBody: redact(string(respBody)),
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{
+		Interactions: append([]Interaction(nil), r.interactions...),
+	}
+}
+
+// Replayer is a transport which answers requests from a cassette without
+// network access. Each request is answered by the first unused interaction
+// with the same method, URI and body. A request with no match gets a 400
+// response with error code ErrNoRecordedResponse, which clients don't
+// retry, and the error is also kept for Err in case the code under test
+// ignores it.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	err          error
+}
+
+// NewReplayer returns a replayer for a cassette.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+}
+
+// RoundTrip answers a request with its recorded response.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	uri := req.URL.RequestURI()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Method != req.Method || in.URI != uri ||
+			in.Body != body {
+			continue
+		}
+		r.used[i] = true
+		return response(req, in.Status, in.Header, in.ResponseBody), nil
+	}
+
+	err = fmt.Errorf("bitxtest: no recorded response for %s %s %q",
+		req.Method, uri, body)
+	if r.err == nil {
+		r.err = err
+	}
+	// An error from RoundTrip would look like a network failure, which
+	// clients retry, so reply with a client error instead.
+	b, _ := json.Marshal(map[string]string{
+		"error":      err.Error(),
+		"error_code": "ErrNoRecordedResponse",
+	})
+	header := http.Header{"Content-Type": {"application/json"}}
+	return response(req, http.StatusBadRequest, header, string(b)), nil
+}
+
+// response builds a response to req.
+func response(req *http.Request, status int, header http.Header,
+	body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// Err returns the error for the first request which had no recorded
+// response, if any.
+func (r *Replayer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Unused returns the interactions which haven't been replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var l []Interaction
+	for i, in := range r.interactions {
+		if !r.used[i] {
+			l = append(l, in)
+		}
+	}
+	return l
+}
+
+// String formats the interaction's request, e.g. for test failures.
+func (in Interaction) String() string {
+	return in.Method + " " + in.URI
+}
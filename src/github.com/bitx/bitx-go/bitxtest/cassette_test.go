@@ -0,0 +1,96 @@
+package bitxtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestRecordReplay(t *testing.T) {
+	s := NewServer()
+	s.SetBalance("ZAR", 1000*bitx.Unit)
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5000"))
+
+	rec := NewRecorder(nil)
+	c := s.Client(bitx.WithTransport(rec))
+	t1, err := c.Ticker("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.PostOrder("XBTZAR", bitx.BID, amount("0.01"), amount("100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	dir, err := ioutil.TempDir("", "bitxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+	if err := rec.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(b), KeySecret) ||
+		strings.Contains(string(b), KeyID) {
+		t.Errorf("Expected credentials to be redacted:\n%s", b)
+	}
+
+	cas, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := NewReplayer(cas)
+	c = bitx.NewClient(KeyID, KeySecret, bitx.WithTransport(rp))
+	t2, err := c.Ticker("XBTZAR")
+	if err != nil || t2.Ask != t1.Ask || !t2.Timestamp.Equal(t1.Timestamp) {
+		t.Errorf("Expected replayed ticker %+v, got %+v (%v)", t1, t2, err)
+	}
+	if len(rp.Unused()) != 1 {
+		t.Errorf("Expected 1 unused interaction, got %v", rp.Unused())
+	}
+	if _, err := c.PostOrder("XBTZAR", bitx.BID, amount("0.02"),
+		amount("100")); err == nil {
+		t.Errorf("Expected unmatched request to fail")
+	}
+	if rp.Err() == nil {
+		t.Errorf("Expected replayer to keep the unmatched request error")
+	}
+	id2, err := c.PostOrder("XBTZAR", bitx.BID, amount("0.01"), amount("100"))
+	if err != nil || id2 != id {
+		t.Errorf("Expected replayed order %s, got %s (%v)", id, id2, err)
+	}
+}
+
+func TestReplayerUnmatchedNotRetried(t *testing.T) {
+	var attempts int
+	count := func(ctx context.Context, req *bitx.Request,
+		invoke bitx.Invoker) error {
+		attempts++
+		return invoke(ctx, req)
+	}
+	rp := NewReplayer(&Cassette{})
+	c := bitx.NewClient(KeyID, KeySecret, bitx.WithTransport(rp),
+		bitx.WithRetry(3, time.Hour), bitx.WithInterceptors(count))
+
+	_, err := c.Ticker("XBTZAR")
+	e, ok := err.(*bitx.APIError)
+	if !ok || e.Code != "ErrNoRecordedResponse" || e.Retryable {
+		t.Errorf("Expected non-retryable ErrNoRecordedResponse, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+	if rp.Err() == nil {
+		t.Errorf("Expected replayer to keep the unmatched request error")
+	}
+}
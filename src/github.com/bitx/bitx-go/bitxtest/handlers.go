@@ -0,0 +1,855 @@
+package bitxtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+var (
+	errUnauthorised = &apiError{http.StatusUnauthorized,
+		"ErrUnauthorised", "Unauthorised"}
+	errMethodNotAllowed = &apiError{http.StatusMethodNotAllowed,
+		"ErrMethodNotAllowed", "Method not allowed"}
+	errInvalidPair = &apiError{http.StatusBadRequest,
+		"ErrInvalidPair", "Invalid pair"}
+	errInvalidOrderType = &apiError{http.StatusBadRequest,
+		"ErrInvalidOrderType", "Invalid order type"}
+	errInsufficientBalance = &apiError{http.StatusBadRequest,
+		"ErrInsufficientBalance", "Insufficient balance"}
+	errOrderNotFound = &apiError{http.StatusNotFound,
+		"ErrOrderNotFound", "Order not found"}
+	errOrderNotPending = &apiError{http.StatusBadRequest,
+		"ErrOrderNotPending", "Order is not pending"}
+	errInvalidState = &apiError{http.StatusBadRequest,
+		"ErrInvalidState", "Invalid state"}
+	errAccountNotFound = &apiError{http.StatusNotFound,
+		"ErrAccountNotFound", "Account not found"}
+	errInvalidRowRange = &apiError{http.StatusBadRequest,
+		"ErrInvalidRowRange", "Invalid row range"}
+	errInvalidAsset = &apiError{http.StatusBadRequest,
+		"ErrInvalidAsset", "Invalid asset"}
+	errInvalidWithdrawalType = &apiError{http.StatusBadRequest,
+		"ErrInvalidWithdrawalType", "Invalid withdrawal type"}
+	errWithdrawalNotFound = &apiError{http.StatusNotFound,
+		"ErrWithdrawalNotFound", "Withdrawal not found"}
+	errWithdrawalNotPending = &apiError{http.StatusBadRequest,
+		"ErrWithdrawalNotPending", "Withdrawal is not pending"}
+	errMarketUnavailable = &apiError{http.StatusBadRequest,
+		"ErrMarketUnavailable", "Market is not accepting orders"}
+	errVolumeOutOfRange = &apiError{http.StatusBadRequest,
+		"ErrVolumeOutOfRange", "Volume is outside the market's limits"}
+	errPriceOutOfRange = &apiError{http.StatusBadRequest,
+		"ErrPriceOutOfRange", "Price is outside the market's limits"}
+	errTooPrecise = &apiError{http.StatusBadRequest,
+		"ErrTooPrecise", "Volume or price has too many decimal places"}
+	errInsufficientLiquidity = &apiError{http.StatusBadRequest,
+		"ErrInsufficientLiquidity", "Insufficient liquidity"}
+	errQuoteNotFound = &apiError{http.StatusNotFound,
+		"ErrQuoteNotFound", "Quote not found"}
+	errQuoteExpired = &apiError{http.StatusBadRequest,
+		"ErrQuoteExpired", "Quote has expired"}
+	errQuoteNotActive = &apiError{http.StatusBadRequest,
+		"ErrQuoteNotActive", "Quote has been exercised or discarded"}
+)
+
+func errInvalidAmount(field string) *apiError {
+	return &apiError{http.StatusBadRequest, "ErrInvalidAmount",
+		"Invalid " + field}
+}
+
+func errInvalidValue(field string) *apiError {
+	return &apiError{http.StatusBadRequest, "ErrInvalidValue",
+		"Invalid " + field}
+}
+
+type handlerFunc func(r *http.Request) (interface{}, error)
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/exchange/1/markets", s.public("GET", s.listMarkets))
+	mux.Handle("/api/1/ticker", s.public("GET", s.ticker))
+	mux.Handle("/api/1/tickers", s.public("GET", s.listTickers))
+	mux.Handle("/api/1/orderbook", s.public("GET", s.orderBook))
+	mux.Handle("/api/1/trades", s.public("GET", s.publicTrades))
+	mux.Handle("/api/1/postorder", s.private("POST", s.postOrder))
+	mux.Handle("/api/1/marketorder", s.private("POST", s.postMarketOrder))
+	mux.Handle("/api/1/listorders", s.private("GET", s.listOrders))
+	mux.Handle("/api/1/orders/", s.private("GET", s.getOrder))
+	mux.Handle("/api/1/stoporder", s.private("POST", s.stopOrder))
+	mux.Handle("/api/1/listtrades", s.private("GET", s.listTrades))
+	mux.Handle("/api/1/accounts/", s.private("GET", s.listTransactions))
+	mux.Handle("/api/1/fee_info", s.private("GET", s.feeInfo))
+	mux.Handle("/api/1/balance", s.private("GET", s.getBalance))
+	mux.Handle("/api/1/send", s.private("POST", s.send))
+	mux.Handle("/api/1/funding_address", s.serve(methods{
+		"GET":  s.getFundingAddress,
+		"POST": s.createFundingAddress,
+	}, true))
+	mux.Handle("/api/1/quotes", s.private("POST", s.createQuote))
+	mux.Handle("/api/1/quotes/", s.serve(methods{
+		"GET":    s.getQuote,
+		"PUT":    s.exerciseQuote,
+		"DELETE": s.discardQuote,
+	}, true))
+	mux.Handle("/api/1/withdrawals", s.serve(methods{
+		"GET":  s.listWithdrawals,
+		"POST": s.requestWithdrawal,
+	}, true))
+	mux.Handle("/api/1/withdrawals/", s.serve(methods{
+		"GET":    s.getWithdrawal,
+		"DELETE": s.cancelWithdrawal,
+	}, true))
+	return mux
+}
+
+// methods maps HTTP methods to the handlers for an endpoint.
+type methods map[string]handlerFunc
+
+func (s *Server) public(method string, h handlerFunc) http.Handler {
+	return s.serve(methods{method: h}, false)
+}
+
+func (s *Server) private(method string, h handlerFunc) http.Handler {
+	return s.serve(methods{method: h}, true)
+}
+
+func (s *Server) serve(hs methods, auth bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp interface{}
+		var err error
+		h, ok := hs[r.Method]
+		if id, secret, authOK := r.BasicAuth(); auth &&
+			(!authOK || id != KeyID || secret != KeySecret) {
+			err = errUnauthorised
+		} else if !ok {
+			err = errMethodNotAllowed
+		} else {
+			s.mu.Lock()
+			resp, err = h(r)
+			s.mu.Unlock()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			e, ok := err.(*apiError)
+			if !ok {
+				e = &apiError{http.StatusInternalServerError,
+					"ErrInternal", err.Error()}
+			}
+			w.WriteHeader(e.status)
+			resp = map[string]string{"error": e.message, "error_code": e.code}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func formAmount(r *http.Request, field string) (bitx.Amount, error) {
+	v, err := bitx.ParseAmount(r.FormValue(field))
+	if err != nil || v <= 0 {
+		return 0, errInvalidAmount(field)
+	}
+	return v, nil
+}
+
+// formInt parses an optional integer field, returning def if it is empty.
+func formInt(r *http.Request, field string, def int) (int, error) {
+	v := r.FormValue(field)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func formPair(r *http.Request) (string, error) {
+	pair := r.FormValue("pair")
+	if !validPair(pair) {
+		return "", errInvalidPair
+	}
+	return pair, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / 1e6
+}
+
+func (s *Server) listMarkets(r *http.Request) (interface{}, error) {
+	pairs := make([]string, 0, len(s.markets))
+	for pair := range s.markets {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	resp := make([]map[string]interface{}, 0, len(pairs))
+	for _, pair := range pairs {
+		m := s.markets[pair]
+		resp = append(resp, map[string]interface{}{
+			"market_id":        m.Pair,
+			"trading_status":   string(m.Status),
+			"base_currency":    m.BaseCurrency,
+			"counter_currency": m.CounterCurrency,
+			"min_volume":       m.MinVolume.String(),
+			"max_volume":       m.MaxVolume.String(),
+			"volume_scale":     m.VolumeScale,
+			"min_price":        m.MinPrice.String(),
+			"max_price":        m.MaxPrice.String(),
+			"price_scale":      m.PriceScale,
+		})
+	}
+	return map[string]interface{}{"markets": resp}, nil
+}
+
+func (s *Server) ticker(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.marshalTicker(pair), nil
+}
+
+// listTickers lists the pairs which have had orders or trades.
+func (s *Server) listTickers(r *http.Request) (interface{}, error) {
+	seen := make(map[string]bool)
+	for pair, b := range s.books {
+		if len(b.bids) > 0 || len(b.asks) > 0 {
+			seen[pair] = true
+		}
+	}
+	for pair := range s.trades {
+		seen[pair] = true
+	}
+	pairs := make([]string, 0, len(seen))
+	for pair := range seen {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	resp := make([]map[string]interface{}, 0, len(pairs))
+	for _, pair := range pairs {
+		resp = append(resp, s.marshalTicker(pair))
+	}
+	return map[string]interface{}{"tickers": resp}, nil
+}
+
+func (s *Server) marshalTicker(pair string) map[string]interface{} {
+	var bid, ask, last, volume bitx.Amount
+	if b, ok := s.books[pair]; ok && len(b.bids) > 0 {
+		bid = b.bids[0].price
+	}
+	if b, ok := s.books[pair]; ok && len(b.asks) > 0 {
+		ask = b.asks[0].price
+	}
+	trades := s.trades[pair]
+	if len(trades) > 0 {
+		last = trades[len(trades)-1].price
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	for _, t := range trades {
+		if t.timestamp.After(since) {
+			volume += t.volume
+		}
+	}
+	return map[string]interface{}{
+		"timestamp":              millis(time.Now()),
+		"pair":                   pair,
+		"bid":                    bid.String(),
+		"ask":                    ask.String(),
+		"last_trade":             last.String(),
+		"rolling_24_hour_volume": volume.String(),
+	}
+}
+
+type orderBookEntry struct {
+	Price  string `json:"price"`
+	Volume string `json:"volume"`
+}
+
+// aggregate sums the remaining volume of orders at each price.
+func aggregate(orders []*order) []orderBookEntry {
+	entries := make([]orderBookEntry, 0)
+	var price, volume bitx.Amount
+	for i, o := range orders {
+		if i > 0 && o.price != price {
+			entries = append(entries,
+				orderBookEntry{price.String(), volume.String()})
+			volume = 0
+		}
+		price = o.price
+		volume += o.remaining
+	}
+	if len(orders) > 0 {
+		entries = append(entries,
+			orderBookEntry{price.String(), volume.String()})
+	}
+	return entries
+}
+
+func (s *Server) orderBook(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	b := s.book(pair)
+	return map[string]interface{}{
+		"timestamp": millis(time.Now()),
+		"bids":      aggregate(b.bids),
+		"asks":      aggregate(b.asks),
+	}, nil
+}
+
+func (s *Server) publicTrades(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	trades := s.trades[pair]
+	resp := make([]map[string]interface{}, 0, len(trades))
+	for i := len(trades) - 1; i >= 0 && len(resp) < 100; i-- {
+		resp = append(resp, map[string]interface{}{
+			"timestamp": millis(trades[i].timestamp),
+			"price":     trades[i].price.String(),
+			"volume":    trades[i].volume.String(),
+		})
+	}
+	return map[string]interface{}{"trades": resp}, nil
+}
+
+func (s *Server) postOrder(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	typ := bitx.OrderType(r.FormValue("type"))
+	if typ != bitx.BID && typ != bitx.ASK {
+		return nil, errInvalidOrderType
+	}
+	volume, err := formAmount(r, "volume")
+	if err != nil {
+		return nil, err
+	}
+	price, err := formAmount(r, "price")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLimits(pair, volume, price); err != nil {
+		return nil, err
+	}
+
+	o := s.newOrder(pair, typ, volume, price)
+	o.user = true
+	if !s.reserve(o) {
+		delete(s.orders, o.id)
+		return nil, errInsufficientBalance
+	}
+	s.history = append(s.history, o)
+	s.place(o)
+
+	return map[string]string{"order_id": o.id}, nil
+}
+
+func (s *Server) postMarketOrder(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	typ := bitx.OrderType(r.FormValue("type"))
+	var volume, counterVolume bitx.Amount
+	switch typ {
+	case bitx.BUY:
+		counterVolume, err = formAmount(r, "counter_volume")
+	case bitx.SELL:
+		volume, err = formAmount(r, "base_volume")
+	default:
+		return nil, errInvalidOrderType
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o := s.newOrder(pair, typ, volume, 0)
+	o.user = true
+	o.counterVolume = counterVolume
+	if !s.reserve(o) {
+		delete(s.orders, o.id)
+		return nil, errInsufficientBalance
+	}
+	s.history = append(s.history, o)
+	s.placeMarket(o)
+
+	return map[string]string{"order_id": o.id}, nil
+}
+
+// checkLimits checks a limit order against its market, if one is set.
+func (s *Server) checkLimits(pair string, volume, price bitx.Amount) error {
+	m, ok := s.markets[pair]
+	switch {
+	case !ok:
+		return nil
+	case m.Status != bitx.Active && m.Status != bitx.PostOnly:
+		return errMarketUnavailable
+	case volume%m.VolumeTick() != 0 || price%m.PriceTick() != 0:
+		return errTooPrecise
+	case volume < m.MinVolume || volume > m.MaxVolume:
+		return errVolumeOutOfRange
+	case price < m.MinPrice || price > m.MaxPrice:
+		return errPriceOutOfRange
+	}
+	return nil
+}
+
+func marshalOrder(o *order) map[string]interface{} {
+	var completed int64
+	if !o.completed.IsZero() {
+		completed = millis(o.completed)
+	}
+	return map[string]interface{}{
+		"order_id":             o.id,
+		"pair":                 o.pair,
+		"creation_timestamp":   millis(o.created),
+		"completed_timestamp":  completed,
+		"expiration_timestamp": 0,
+		"type":                 string(o.typ),
+		"state":                string(o.state),
+		"limit_price":          o.price.String(),
+		"limit_volume":         o.volume.String(),
+		"base":                 o.base.String(),
+		"counter":              o.counter.String(),
+		"fee_base":             o.feeBase.String(),
+		"fee_counter":          o.feeCounter.String(),
+	}
+}
+
+func (s *Server) listOrders(r *http.Request) (interface{}, error) {
+	pair := r.FormValue("pair")
+	state := bitx.OrderState(r.FormValue("state"))
+	if state != "" && state != bitx.Pending && state != bitx.Complete {
+		return nil, errInvalidState
+	}
+	limit, err := formInt(r, "limit", 100)
+	if err != nil || limit <= 0 || limit > 1000 {
+		return nil, errInvalidValue("limit")
+	}
+	before, err := formInt(r, "created_before", 0)
+	if err != nil {
+		return nil, errInvalidValue("created_before")
+	}
+
+	resp := make([]map[string]interface{}, 0)
+	for i := len(s.history) - 1; i >= 0 && len(resp) < limit; i-- {
+		o := s.history[i]
+		if pair != "" && o.pair != pair ||
+			state != "" && o.state != state ||
+			before != 0 && millis(o.created) >= int64(before) {
+			continue
+		}
+		resp = append(resp, marshalOrder(o))
+	}
+	return map[string]interface{}{"orders": resp}, nil
+}
+
+func (s *Server) getOrder(r *http.Request) (interface{}, error) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/1/orders/")
+	o, ok := s.orders[id]
+	if !ok || !o.user {
+		return nil, errOrderNotFound
+	}
+	return marshalOrder(o), nil
+}
+
+func (s *Server) stopOrder(r *http.Request) (interface{}, error) {
+	o, ok := s.orders[r.FormValue("order_id")]
+	if !ok || !o.user {
+		return nil, errOrderNotFound
+	}
+	if o.state != bitx.Pending {
+		return nil, errOrderNotPending
+	}
+	s.remove(o)
+	s.complete(o)
+	return map[string]bool{"success": true}, nil
+}
+
+func (s *Server) listTrades(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := formInt(r, "limit", 100)
+	if err != nil || limit <= 0 || limit > 1000 {
+		return nil, errInvalidValue("limit")
+	}
+	since, err := formInt(r, "since", 0)
+	if err != nil {
+		return nil, errInvalidValue("since")
+	}
+
+	resp := make([]map[string]interface{}, 0)
+	for _, f := range s.fills {
+		if len(resp) >= limit {
+			break
+		}
+		if f.order.pair != pair || millis(f.timestamp) < int64(since) {
+			continue
+		}
+		resp = append(resp, map[string]interface{}{
+			"pair":        f.order.pair,
+			"sequence":    f.sequence,
+			"order_id":    f.order.id,
+			"type":        string(f.order.typ),
+			"timestamp":   millis(f.timestamp),
+			"price":       f.price.String(),
+			"volume":      f.volume.String(),
+			"base":        f.volume.String(),
+			"counter":     f.volume.Mul(f.price).String(),
+			"fee_base":    f.feeBase.String(),
+			"fee_counter": f.feeCounter.String(),
+			"is_buy":      isBuy(f.order.typ),
+			"is_maker":    f.maker,
+		})
+	}
+	return map[string]interface{}{"trades": resp}, nil
+}
+
+func (s *Server) listTransactions(r *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/1/accounts/")
+	if !strings.HasSuffix(path, "/transactions") {
+		return nil, errAccountNotFound
+	}
+	id := strings.TrimSuffix(path, "/transactions")
+	var b *balance
+	for _, other := range s.balances {
+		if other.accountID == id {
+			b = other
+		}
+	}
+	if b == nil {
+		return nil, errAccountNotFound
+	}
+
+	minRow, err := formInt(r, "min_row", 0)
+	if err != nil {
+		return nil, errInvalidValue("min_row")
+	}
+	maxRow, err := formInt(r, "max_row", 0)
+	if err != nil {
+		return nil, errInvalidValue("max_row")
+	}
+	if minRow < 1 || maxRow <= minRow || maxRow-minRow > 1000 {
+		return nil, errInvalidRowRange
+	}
+
+	resp := make([]map[string]interface{}, 0)
+	for _, t := range b.ledger {
+		if t.RowIndex < int64(minRow) || t.RowIndex >= int64(maxRow) {
+			continue
+		}
+		resp = append(resp, map[string]interface{}{
+			"row_index":       t.RowIndex,
+			"timestamp":       millis(t.Timestamp),
+			"description":     t.Description,
+			"currency":        t.Currency,
+			"balance_delta":   json.Number(t.BalanceDelta.String()),
+			"available_delta": json.Number(t.AvailableDelta.String()),
+			"balance":         json.Number(t.Balance.String()),
+			"available":       json.Number(t.Available.String()),
+		})
+	}
+	return map[string]interface{}{"id": id, "transactions": resp}, nil
+}
+
+func (s *Server) feeInfo(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	var volume bitx.Amount
+	since := time.Now().Add(-30 * 24 * time.Hour)
+	for _, f := range s.fills {
+		if f.order.pair == pair && f.timestamp.After(since) {
+			volume += f.volume
+		}
+	}
+	return map[string]string{
+		"maker_fee":         s.makerFee.String(),
+		"taker_fee":         s.takerFee.String(),
+		"thirty_day_volume": volume.String(),
+	}, nil
+}
+
+func (s *Server) getBalance(r *http.Request) (interface{}, error) {
+	assets := []string{r.FormValue("asset")}
+	if assets[0] == "" {
+		assets = assets[:0]
+		for asset := range s.balances {
+			assets = append(assets, asset)
+		}
+		sort.Strings(assets)
+	}
+	resp := make([]map[string]string, 0, len(assets))
+	for _, asset := range assets {
+		b := s.balance(asset)
+		resp = append(resp, map[string]string{
+			"account_id":  b.accountID,
+			"asset":       asset,
+			"balance":     b.balance.String(),
+			"reserved":    b.reserved.String(),
+			"unconfirmed": b.unconfirmed.String(),
+		})
+	}
+	return map[string]interface{}{"balance": resp}, nil
+}
+
+func (s *Server) send(r *http.Request) (interface{}, error) {
+	amount, err := formAmount(r, "amount")
+	if err != nil {
+		return nil, err
+	}
+	currency := r.FormValue("currency")
+	b := s.balance(currency)
+	if b.balance-b.reserved < amount {
+		return nil, errInsufficientBalance
+	}
+	s.post(currency, "Sent to "+r.FormValue("address"), -amount, 0)
+	s.sends = append(s.sends, Send{
+		Amount:      r.FormValue("amount"),
+		Currency:    r.FormValue("currency"),
+		Address:     r.FormValue("address"),
+		Description: r.FormValue("description"),
+		Message:     r.FormValue("message"),
+	})
+	return map[string]bool{"success": true}, nil
+}
+
+func marshalFundingAddress(a *address) map[string]string {
+	return map[string]string{
+		"asset":             a.asset,
+		"address":           a.address,
+		"total_received":    a.received.String(),
+		"total_unconfirmed": "0",
+	}
+}
+
+func formAsset(r *http.Request) (string, error) {
+	asset := r.FormValue("asset")
+	if len(asset) != 3 || strings.ToUpper(asset) != asset {
+		return "", errInvalidAsset
+	}
+	return asset, nil
+}
+
+func (s *Server) getFundingAddress(r *http.Request) (interface{}, error) {
+	asset, err := formAsset(r)
+	if err != nil {
+		return nil, err
+	}
+	addrs := s.addresses[asset]
+	if len(addrs) == 0 {
+		return marshalFundingAddress(s.newAddress(asset)), nil
+	}
+	return marshalFundingAddress(addrs[0]), nil
+}
+
+func (s *Server) createFundingAddress(r *http.Request) (interface{}, error) {
+	asset, err := formAsset(r)
+	if err != nil {
+		return nil, err
+	}
+	return marshalFundingAddress(s.newAddress(asset)), nil
+}
+
+func marshalWithdrawal(wd *withdrawal) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         wd.id,
+		"status":     string(wd.status),
+		"created_at": millis(wd.created),
+		"type":       wd.typ,
+		"currency":   wd.currency,
+		"amount":     wd.amount.String(),
+		"fee":        "0",
+	}
+}
+
+func (s *Server) listWithdrawals(r *http.Request) (interface{}, error) {
+	resp := make([]map[string]interface{}, 0, len(s.withdrawals))
+	for i := len(s.withdrawals) - 1; i >= 0; i-- {
+		resp = append(resp, marshalWithdrawal(s.withdrawals[i]))
+	}
+	return map[string]interface{}{"withdrawals": resp}, nil
+}
+
+func (s *Server) requestWithdrawal(r *http.Request) (interface{}, error) {
+	typ := r.FormValue("type")
+	i := strings.IndexByte(typ, '_')
+	if i != 3 {
+		return nil, errInvalidWithdrawalType
+	}
+	amount, err := formAmount(r, "amount")
+	if err != nil {
+		return nil, err
+	}
+	currency := typ[:i]
+	b := s.balance(currency)
+	if b.balance-b.reserved < amount {
+		return nil, errInsufficientBalance
+	}
+
+	s.lastID++
+	wd := &withdrawal{
+		id:       strconv.FormatInt(s.lastID, 10),
+		status:   bitx.WithdrawalPending,
+		created:  time.Now(),
+		typ:      typ,
+		currency: currency,
+		amount:   amount,
+	}
+	s.withdrawals = append(s.withdrawals, wd)
+	s.post(currency, "Reserved for withdrawal "+wd.id, 0, amount)
+	return marshalWithdrawal(wd), nil
+}
+
+func (s *Server) withdrawal(r *http.Request) (*withdrawal, error) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/1/withdrawals/")
+	for _, wd := range s.withdrawals {
+		if wd.id == id {
+			return wd, nil
+		}
+	}
+	return nil, errWithdrawalNotFound
+}
+
+func (s *Server) getWithdrawal(r *http.Request) (interface{}, error) {
+	wd, err := s.withdrawal(r)
+	if err != nil {
+		return nil, err
+	}
+	return marshalWithdrawal(wd), nil
+}
+
+func (s *Server) cancelWithdrawal(r *http.Request) (interface{}, error) {
+	wd, err := s.withdrawal(r)
+	if err != nil {
+		return nil, err
+	}
+	if wd.status != bitx.WithdrawalPending {
+		return nil, errWithdrawalNotPending
+	}
+	wd.status = bitx.WithdrawalCancelled
+	s.post(wd.currency, "Cancelled withdrawal "+wd.id, 0, -wd.amount)
+	return marshalWithdrawal(wd), nil
+}
+
+func marshalQuote(q *quote) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             q.id,
+		"type":           string(q.typ),
+		"pair":           q.pair,
+		"base_amount":    q.base.String(),
+		"counter_amount": q.counter.String(),
+		"created_at":     millis(q.created),
+		"expires_at":     millis(q.expires),
+		"discarded":      q.discarded,
+		"exercised":      q.exercised,
+	}
+}
+
+func (s *Server) createQuote(r *http.Request) (interface{}, error) {
+	pair, err := formPair(r)
+	if err != nil {
+		return nil, err
+	}
+	typ := bitx.OrderType(r.FormValue("type"))
+	if typ != bitx.BUY && typ != bitx.SELL {
+		return nil, errInvalidOrderType
+	}
+	base, err := formAmount(r, "base_amount")
+	if err != nil {
+		return nil, err
+	}
+	counter, ok := s.quotePrice(pair, typ, base)
+	if !ok {
+		return nil, errInsufficientLiquidity
+	}
+
+	s.lastID++
+	now := time.Now()
+	q := &quote{
+		id:      strconv.FormatInt(s.lastID, 10),
+		typ:     typ,
+		pair:    pair,
+		base:    base,
+		counter: counter,
+		created: now,
+		expires: now.Add(s.quoteTTL),
+	}
+	s.quotes[q.id] = q
+	return marshalQuote(q), nil
+}
+
+func (s *Server) quote(r *http.Request) (*quote, error) {
+	q, ok := s.quotes[strings.TrimPrefix(r.URL.Path, "/api/1/quotes/")]
+	if !ok {
+		return nil, errQuoteNotFound
+	}
+	return q, nil
+}
+
+func (s *Server) getQuote(r *http.Request) (interface{}, error) {
+	q, err := s.quote(r)
+	if err != nil {
+		return nil, err
+	}
+	return marshalQuote(q), nil
+}
+
+func (s *Server) exerciseQuote(r *http.Request) (interface{}, error) {
+	q, err := s.quote(r)
+	if err != nil {
+		return nil, err
+	}
+	if q.exercised || q.discarded {
+		return nil, errQuoteNotActive
+	}
+	if !time.Now().Before(q.expires) {
+		return nil, errQuoteExpired
+	}
+
+	base, counter := splitPair(q.pair)
+	from, to, spend, receive := counter, base, q.counter, q.base
+	if q.typ == bitx.SELL {
+		from, to, spend, receive = base, counter, q.base, q.counter
+	}
+	b := s.balance(from)
+	if b.balance-b.reserved < spend {
+		return nil, errInsufficientBalance
+	}
+	s.post(from, "Exercised quote "+q.id, -spend, 0)
+	s.post(to, "Exercised quote "+q.id, receive, 0)
+	q.exercised = true
+	return marshalQuote(q), nil
+}
+
+func (s *Server) discardQuote(r *http.Request) (interface{}, error) {
+	q, err := s.quote(r)
+	if err != nil {
+		return nil, err
+	}
+	if q.exercised || q.discarded {
+		return nil, errQuoteNotActive
+	}
+	q.discarded = true
+	return marshalQuote(q), nil
+}
@@ -0,0 +1,25 @@
+package bitxtest
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/bitx/bitx-go"
+)
+
+// Fail returns a client interceptor which fails requests with the given
+// method and path with err instead of sending them, e.g. to test how code
+// handles an endpoint being down. An empty method matches any method. The
+// error is retried if the client retries it, so a retryable *bitx.APIError
+// fails every attempt.
+//
+//	c := s.Client(bitx.WithInterceptors(bitxtest.Fail("POST",
+//		"/api/1/postorder", &bitx.APIError{StatusCode: 503})))
+func Fail(method, path string, err error) bitx.Interceptor {
+	return func(ctx context.Context, req *bitx.Request,
+		invoke bitx.Invoker) error {
+		if req.Path == path && (method == "" || req.Method == method) {
+			return err
+		}
+		return invoke(ctx, req)
+	}
+}
@@ -0,0 +1,23 @@
+package bitxtest
+
+import (
+	"testing"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestFail(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", 1000*bitx.Unit)
+
+	down := &bitx.APIError{StatusCode: 503, Message: "down"}
+	c := s.Client(bitx.WithInterceptors(Fail("POST", "/api/1/postorder", down)))
+	_, err := c.PostOrder("XBTZAR", bitx.BID, amount("0.01"), amount("100"))
+	if err != down {
+		t.Errorf("Expected %v, got %v", down, err)
+	}
+	if _, _, err := c.Balance("ZAR"); err != nil {
+		t.Errorf("Expected other endpoints to work, got %v", err)
+	}
+}
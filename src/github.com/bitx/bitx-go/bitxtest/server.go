@@ -0,0 +1,599 @@
+// Package bitxtest provides a fake BitX exchange for testing code which uses
+// the bitx package without network access.
+//
+// The fake implements the public market data endpoints and the private
+// order, balance, send, funding address, withdrawal and quote endpoints. It
+// keeps one account per asset with a transaction ledger, reserves funds for
+// open orders and withdrawals, and fills orders which cross. Orders from
+// other market participants can be placed with Server.PlaceOrder.
+//
+// The package also records and replays real API traffic with Recorder and
+// Replayer, and injects failures into a client with Fail.
+package bitxtest
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+// Credentials accepted by the server for the private API.
+const (
+	KeyID     = "bitxtest_key"
+	KeySecret = "bitxtest_secret"
+)
+
+// Send is a request received by the send endpoint.
+type Send struct {
+	Amount, Currency, Address, Description, Message string
+}
+
+type balance struct {
+	accountID         string
+	balance, reserved bitx.Amount
+	unconfirmed       bitx.Amount
+	ledger            []bitx.Transaction
+}
+
+type order struct {
+	id      string
+	pair    string
+	typ     bitx.OrderType
+	state   bitx.OrderState
+	created time.Time
+	// completed is zero until the order is complete.
+	completed time.Time
+
+	price, volume bitx.Amount
+	remaining     bitx.Amount
+	// counterVolume is the amount a market BUY order may spend.
+	counterVolume bitx.Amount
+	base, counter bitx.Amount
+	// Buy orders pay fees in the base and sell orders in the counter
+	// currency.
+	feeBase, feeCounter bitx.Amount
+	// reserved is the amount still reserved from the account's balance.
+	reserved bitx.Amount
+
+	// user is false for orders placed by other market participants.
+	user bool
+}
+
+type trade struct {
+	timestamp     time.Time
+	price, volume bitx.Amount
+}
+
+// execution is a fill of one of the user's orders.
+type execution struct {
+	sequence            int64
+	order               *order
+	timestamp           time.Time
+	price, volume       bitx.Amount
+	feeBase, feeCounter bitx.Amount
+	maker               bool
+}
+
+// address is a funding address for an asset.
+type address struct {
+	asset, address string
+	received       bitx.Amount
+}
+
+type withdrawal struct {
+	id       string
+	status   bitx.WithdrawalStatus
+	created  time.Time
+	typ      string
+	currency string
+	amount   bitx.Amount
+}
+
+type quote struct {
+	id                   string
+	typ                  bitx.OrderType
+	pair                 string
+	base, counter        bitx.Amount
+	created, expires     time.Time
+	discarded, exercised bool
+}
+
+type book struct {
+	// bids and asks are sorted by priority: best price first, then oldest
+	// order first.
+	bids, asks []*order
+}
+
+// DefaultQuoteTTL is how long quotes are valid for unless changed with
+// Server.SetQuoteTTL.
+const DefaultQuoteTTL = 5 * time.Minute
+
+// Server is a fake BitX exchange listening on a local port.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	lastID        int64
+	lastAccountID int64
+	lastCreated   time.Time
+	balances      map[string]*balance
+	orders        map[string]*order
+	// history holds the user's orders in the order they were placed.
+	history []*order
+	books   map[string]*book
+	trades  map[string][]trade
+	// fills holds executions of the user's orders, oldest first.
+	fills []execution
+	sends []Send
+	// addresses holds the funding addresses for each asset, default first.
+	addresses   map[string][]*address
+	withdrawals []*withdrawal
+	quotes      map[string]*quote
+	quoteTTL    time.Duration
+	// makerFee and takerFee are the fee rates charged on the user's fills.
+	makerFee, takerFee bitx.Amount
+	// markets holds the limits enforced on limit orders by pair. Orders in
+	// other pairs are not checked.
+	markets map[string]bitx.Market
+}
+
+// NewServer starts and returns a new fake exchange with no orders and zero
+// balances. The XBTZAR market accepts volumes from 0.0005 to 100 in steps of
+// 0.0001 and whole prices; use SetMarket to change it or add others. The
+// caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		balances:  make(map[string]*balance),
+		orders:    make(map[string]*order),
+		books:     make(map[string]*book),
+		trades:    make(map[string][]trade),
+		addresses: make(map[string][]*address),
+		quotes:    make(map[string]*quote),
+		quoteTTL:  DefaultQuoteTTL,
+		markets: map[string]bitx.Market{
+			"XBTZAR": {
+				Pair:            "XBTZAR",
+				Status:          bitx.Active,
+				BaseCurrency:    "XBT",
+				CounterCurrency: "ZAR",
+				MinVolume:       bitx.MustParseAmount("0.0005"),
+				MaxVolume:       100 * bitx.Unit,
+				VolumeScale:     4,
+				MinPrice:        bitx.Unit,
+				MaxPrice:        10000000 * bitx.Unit,
+				PriceScale:      0,
+			},
+		},
+	}
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// Client returns a client which talks to the server using its credentials.
+func (s *Server) Client(opts ...bitx.Option) *bitx.Client {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	opts = append([]bitx.Option{bitx.WithBaseURL(u)}, opts...)
+	return bitx.NewClient(KeyID, KeySecret, opts...)
+}
+
+// SetBalance sets the account's balance for an asset.
+func (s *Server) SetBalance(asset string, amount bitx.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.balance(asset)
+	s.post(asset, "Balance adjustment", amount-b.balance, 0)
+}
+
+// SetUnconfirmed sets the account's unconfirmed incoming funds for an asset.
+func (s *Server) SetUnconfirmed(asset string, amount bitx.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balance(asset).unconfirmed = amount
+}
+
+// Balance returns the account's balance and reserved funds for an asset.
+func (s *Server) Balance(asset string) (balance, reserved bitx.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.balance(asset)
+	return b.balance, b.reserved
+}
+
+// AccountID returns the ID of the account holding an asset.
+func (s *Server) AccountID(asset string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance(asset).accountID
+}
+
+// PlaceOrder places a limit order on behalf of another market participant
+// and returns its ID. The order is matched against any crossing orders,
+// including the account's own. The order is checked like one posted to the
+// API.
+func (s *Server) PlaceOrder(pair string, typ bitx.OrderType,
+	volume, price bitx.Amount) (string, error) {
+	switch {
+	case !validPair(pair):
+		return "", errInvalidPair
+	case typ != bitx.BID && typ != bitx.ASK:
+		return "", errInvalidOrderType
+	case volume <= 0:
+		return "", errInvalidAmount("volume")
+	case price <= 0:
+		return "", errInvalidAmount("price")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.newOrder(pair, typ, volume, price)
+	s.place(o)
+	return o.id, nil
+}
+
+// Sends returns the send requests received so far.
+func (s *Server) Sends() []Send {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Send(nil), s.sends...)
+}
+
+// Receive credits amount received at a funding address, as returned by
+// Client.FundingAddress, to the account. It panics if the address is
+// unknown.
+func (s *Server) Receive(addr string, amount bitx.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, addrs := range s.addresses {
+		for _, a := range addrs {
+			if a.address == addr {
+				a.received += amount
+				s.post(a.asset, "Received at "+addr, amount, 0)
+				return
+			}
+		}
+	}
+	panic("bitxtest: unknown address " + addr)
+}
+
+// CompleteWithdrawal marks a pending withdrawal as completed and deducts it
+// from the account. It panics if the withdrawal is not pending.
+func (s *Server) CompleteWithdrawal(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, wd := range s.withdrawals {
+		if wd.id == id && wd.status == bitx.WithdrawalPending {
+			wd.status = bitx.WithdrawalCompleted
+			s.post(wd.currency, "Withdrawal "+id, -wd.amount, -wd.amount)
+			return
+		}
+	}
+	panic("bitxtest: no pending withdrawal " + id)
+}
+
+// SetFees sets the fee rates charged on fills of the account's orders, e.g.
+// bitx.MustParseAmount("0.001") for 0.1%. Fees are zero by default.
+func (s *Server) SetFees(maker, taker bitx.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.makerFee, s.takerFee = maker, taker
+}
+
+// SetMarket sets the limits of a market, which are listed by the markets
+// endpoint and enforced on limit orders in its pair.
+func (s *Server) SetMarket(m bitx.Market) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markets[m.Pair] = m
+}
+
+// SetQuoteTTL sets how long new quotes are valid for.
+func (s *Server) SetQuoteTTL(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quoteTTL = d
+}
+
+func min(a, b bitx.Amount) bitx.Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// validPair reports whether pair looks like XBTZAR.
+func validPair(pair string) bool {
+	return len(pair) == 6 && strings.ToUpper(pair) == pair
+}
+
+// isBuy reports whether orders of the type buy the base currency.
+func isBuy(typ bitx.OrderType) bool {
+	return typ == bitx.BID || typ == bitx.BUY
+}
+
+// splitPair returns the base and counter assets of a valid pair like XBTZAR.
+func splitPair(pair string) (base, counter string) {
+	return pair[:3], pair[3:]
+}
+
+func (s *Server) balance(asset string) *balance {
+	b, ok := s.balances[asset]
+	if !ok {
+		s.lastAccountID++
+		b = &balance{accountID: strconv.FormatInt(s.lastAccountID, 10)}
+		s.balances[asset] = b
+	}
+	return b
+}
+
+// post changes an account's balance and reserved funds and records the
+// change in its ledger.
+func (s *Server) post(asset, description string,
+	balanceDelta, reservedDelta bitx.Amount) {
+	b := s.balance(asset)
+	b.balance += balanceDelta
+	b.reserved += reservedDelta
+	b.ledger = append(b.ledger, bitx.Transaction{
+		RowIndex:       int64(len(b.ledger) + 1),
+		Timestamp:      time.Now(),
+		Description:    description,
+		Currency:       asset,
+		BalanceDelta:   balanceDelta,
+		AvailableDelta: balanceDelta - reservedDelta,
+		Balance:        b.balance,
+		Available:      b.balance - b.reserved,
+	})
+}
+
+func (s *Server) newAddress(asset string) *address {
+	s.lastID++
+	a := &address{
+		asset:   asset,
+		address: "bitxtest" + asset + strconv.FormatInt(s.lastID, 10),
+	}
+	s.addresses[asset] = append(s.addresses[asset], a)
+	return a
+}
+
+func (s *Server) book(pair string) *book {
+	b, ok := s.books[pair]
+	if !ok {
+		b = &book{}
+		s.books[pair] = b
+	}
+	return b
+}
+
+func (s *Server) newOrder(pair string, typ bitx.OrderType,
+	volume, price bitx.Amount) *order {
+	s.lastID++
+	o := &order{
+		id:        "BXID" + strconv.FormatInt(s.lastID, 10),
+		pair:      pair,
+		typ:       typ,
+		state:     bitx.Pending,
+		created:   s.now(),
+		price:     price,
+		volume:    volume,
+		remaining: volume,
+	}
+	s.orders[o.id] = o
+	return o
+}
+
+// now returns the current time for a new order. Orders are given distinct
+// millisecond timestamps so that they can be paged through by creation time.
+func (s *Server) now() time.Time {
+	t := time.Now()
+	if next := s.lastCreated.Add(time.Millisecond); t.Before(next) {
+		t = next
+	}
+	s.lastCreated = t
+	return t
+}
+
+// quotePrice returns the counter amount for which base can be bought or sold
+// at the best prices in the book. It returns false if the book doesn't have
+// enough volume.
+func (s *Server) quotePrice(pair string, typ bitx.OrderType,
+	base bitx.Amount) (bitx.Amount, bool) {
+	b := s.book(pair)
+	side := b.asks
+	if typ == bitx.SELL {
+		side = b.bids
+	}
+	var counter bitx.Amount
+	for _, o := range side {
+		volume := min(base, o.remaining)
+		counter += volume.Mul(o.price)
+		base -= volume
+		if base == 0 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// place matches the order against the book and rests any remaining volume.
+func (s *Server) place(o *order) {
+	b := s.book(o.pair)
+	other := &b.asks
+	crosses := func(price bitx.Amount) bool { return price <= o.price }
+	if o.typ == bitx.ASK {
+		other = &b.bids
+		crosses = func(price bitx.Amount) bool { return price >= o.price }
+	}
+
+	for o.remaining > 0 && len(*other) > 0 && crosses((*other)[0].price) {
+		s.match(o, other, min(o.remaining, (*other)[0].remaining))
+	}
+
+	if o.remaining == 0 {
+		s.complete(o)
+		return
+	}
+	s.insert(b, o)
+}
+
+// placeMarket fills a market order at the best prices in the book until it
+// is exhausted or the book is empty. A BUY order spends up to the reserved
+// counter amount and a SELL order sells its volume. Any unfilled part is
+// cancelled.
+func (s *Server) placeMarket(o *order) {
+	b := s.book(o.pair)
+	if o.typ == bitx.SELL {
+		for o.remaining > 0 && len(b.bids) > 0 {
+			s.match(o, &b.bids, min(o.remaining, b.bids[0].remaining))
+		}
+	} else {
+		for len(b.asks) > 0 {
+			maker := b.asks[0]
+			budget := o.reserved.Div(maker.price)
+			volume := min(budget, maker.remaining)
+			if volume <= 0 {
+				break
+			}
+			s.match(o, &b.asks, volume)
+		}
+	}
+	o.remaining = 0
+	s.complete(o)
+}
+
+// match trades volume between o and the first order on the other side of
+// the book at that order's price.
+func (s *Server) match(o *order, other *[]*order, volume bitx.Amount) {
+	maker := (*other)[0]
+	s.fill(maker, volume, maker.price, true)
+	s.fill(o, volume, maker.price, false)
+	s.trades[o.pair] = append(s.trades[o.pair],
+		trade{time.Now(), maker.price, volume})
+	if maker.remaining == 0 {
+		*other = (*other)[1:]
+		s.complete(maker)
+	}
+}
+
+func (s *Server) insert(b *book, o *order) {
+	side := &b.bids
+	after := func(other *order) bool { return other.price < o.price }
+	if o.typ == bitx.ASK {
+		side = &b.asks
+		after = func(other *order) bool { return other.price > o.price }
+	}
+	i := sort.Search(len(*side), func(i int) bool { return after((*side)[i]) })
+	*side = append(*side, nil)
+	copy((*side)[i+1:], (*side)[i:])
+	(*side)[i] = o
+}
+
+func (s *Server) remove(o *order) {
+	b := s.book(o.pair)
+	for _, side := range []*[]*order{&b.bids, &b.asks} {
+		for i, other := range *side {
+			if other == o {
+				*side = append((*side)[:i], (*side)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// reserve reserves the funds needed for a new user order. It returns false
+// if the available balance is insufficient.
+func (s *Server) reserve(o *order) bool {
+	base, counter := splitPair(o.pair)
+	asset, amount := base, o.volume
+	if o.typ == bitx.BID {
+		asset, amount = counter, o.volume.Mul(o.price)
+	} else if o.typ == bitx.BUY {
+		asset, amount = counter, o.counterVolume
+	}
+	b := s.balance(asset)
+	if b.balance-b.reserved < amount {
+		return false
+	}
+	s.post(asset, "Reserved for order "+o.id, 0, amount)
+	o.reserved = amount
+	return true
+}
+
+// fill executes volume of the order at price and settles and records the
+// user's balances and trades. maker is true for the resting order.
+func (s *Server) fill(o *order, volume, price bitx.Amount, maker bool) {
+	counter := volume.Mul(price)
+	o.remaining -= volume
+	o.base += volume
+	o.counter += counter
+	if !o.user {
+		return
+	}
+
+	rate := s.takerFee
+	if maker {
+		rate = s.makerFee
+	}
+	var feeBase, feeCounter bitx.Amount
+	baseAsset, counterAsset := splitPair(o.pair)
+	if isBuy(o.typ) {
+		feeBase = volume.Mul(rate)
+		release := counter
+		if o.typ == bitx.BID {
+			release = volume.Mul(o.price)
+		}
+		release = min(o.reserved, release)
+		s.post(counterAsset, "Bought with order "+o.id, -counter, -release)
+		s.post(baseAsset, "Bought with order "+o.id, volume, 0)
+		o.reserved -= release
+	} else {
+		feeCounter = counter.Mul(rate)
+		release := min(o.reserved, volume)
+		s.post(baseAsset, "Sold with order "+o.id, -volume, -release)
+		s.post(counterAsset, "Sold with order "+o.id, counter, 0)
+		o.reserved -= release
+	}
+	if feeBase != 0 {
+		s.post(baseAsset, "Trading fee for order "+o.id, -feeBase, 0)
+	}
+	if feeCounter != 0 {
+		s.post(counterAsset, "Trading fee for order "+o.id, -feeCounter, 0)
+	}
+	o.feeBase += feeBase
+	o.feeCounter += feeCounter
+
+	s.fills = append(s.fills, execution{
+		sequence:   int64(len(s.fills) + 1),
+		order:      o,
+		timestamp:  time.Now(),
+		price:      price,
+		volume:     volume,
+		feeBase:    feeBase,
+		feeCounter: feeCounter,
+		maker:      maker,
+	})
+}
+
+// complete marks the order as complete and releases any remaining
+// reservation.
+func (s *Server) complete(o *order) {
+	o.state = bitx.Complete
+	o.completed = time.Now()
+	if !o.user || o.reserved == 0 {
+		return
+	}
+	base, counter := splitPair(o.pair)
+	asset := base
+	if isBuy(o.typ) {
+		asset = counter
+	}
+	s.post(asset, "Released reservation for order "+o.id, 0, -o.reserved)
+	o.reserved = 0
+}
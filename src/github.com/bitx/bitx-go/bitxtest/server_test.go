@@ -0,0 +1,303 @@
+package bitxtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitx/bitx-go"
+)
+
+// call sends a request to the server with the given credentials and decodes
+// the JSON response into resp. It returns the HTTP status code.
+func call(t *testing.T, s *Server, id, secret, method, path string,
+	form url.Values, resp interface{}) int {
+	u := s.URL + path
+	var body *strings.Reader
+	if method == "GET" {
+		u += "?" + form.Encode()
+		body = strings.NewReader("")
+	} else {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(id, secret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		t.Fatal(err)
+	}
+	return r.StatusCode
+}
+
+// private is like call with the server's credentials.
+func private(t *testing.T, s *Server, method, path string, form url.Values,
+	resp interface{}) int {
+	return call(t, s, KeyID, KeySecret, method, path, form, resp)
+}
+
+type orderResp struct {
+	OrderId string `json:"order_id"`
+	State   string `json:"state"`
+	Base    string `json:"base"`
+	Counter string `json:"counter"`
+	Error   string `json:"error"`
+}
+
+func postOrder(t *testing.T, s *Server, typ, volume, price string) orderResp {
+	var o orderResp
+	private(t, s, "POST", "/api/1/postorder", url.Values{
+		"pair": {"XBTZAR"}, "type": {typ},
+		"volume": {volume}, "price": {price},
+	}, &o)
+	return o
+}
+
+func getOrder(t *testing.T, s *Server, id string) orderResp {
+	var o orderResp
+	if status := private(t, s, "GET", "/api/1/orders/"+id, nil,
+		&o); status != http.StatusOK {
+		t.Fatalf("Expected order %s, got %d %s", id, status, o.Error)
+	}
+	return o
+}
+
+var amount = bitx.MustParseAmount
+
+func expectBalance(t *testing.T, s *Server, asset string,
+	balance, reserved string) {
+	b, r := s.Balance(asset)
+	if b != amount(balance) || r != amount(reserved) {
+		t.Errorf("Expected %s balance %s (reserved %s), got %s (reserved %s)",
+			asset, balance, reserved, b, r)
+	}
+}
+
+func TestUnauthorised(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	var resp map[string]string
+	status := call(t, s, "wrong", "key", "GET", "/api/1/balance",
+		url.Values{"asset": {"XBT"}}, &resp)
+	if status != http.StatusUnauthorized || resp["error"] == "" {
+		t.Errorf("Expected error for bad credentials, got %d %v", status,
+			resp)
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", amount("10000"))
+
+	id := postOrder(t, s, "BID", "1.5", "5000").OrderId
+	if id == "" {
+		t.Fatal("Expected an order id")
+	}
+	expectBalance(t, s, "ZAR", "10000", "7500")
+
+	var book struct {
+		Bids, Asks []struct{ Price, Volume string }
+	}
+	call(t, s, "", "", "GET", "/api/1/orderbook",
+		url.Values{"pair": {"XBTZAR"}}, &book)
+	if len(book.Bids) != 1 || book.Bids[0].Price != "5000" ||
+		book.Bids[0].Volume != "1.5" || len(book.Asks) != 0 {
+		t.Errorf("Expected our bid in the order book, got %+v", book)
+	}
+
+	// Another participant sells into our bid.
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("4900"))
+	o := getOrder(t, s, id)
+	if o.State != "PENDING" || o.Base != "1" ||
+		o.Counter != "5000" {
+		t.Errorf("Expected partially filled order, got %+v", o)
+	}
+	expectBalance(t, s, "ZAR", "5000", "2500")
+	expectBalance(t, s, "XBT", "1", "0")
+
+	var tk map[string]interface{}
+	call(t, s, "", "", "GET", "/api/1/ticker",
+		url.Values{"pair": {"XBTZAR"}}, &tk)
+	if tk["bid"] != "5000" || tk["last_trade"] != "5000" ||
+		tk["rolling_24_hour_volume"] != "1" {
+		t.Errorf("Unexpected ticker: %+v", tk)
+	}
+
+	stop := url.Values{"order_id": {id}}
+	var resp map[string]interface{}
+	if status := private(t, s, "POST", "/api/1/stoporder", stop,
+		&resp); status != http.StatusOK {
+		t.Fatalf("Expected order to stop, got %d %v", status, resp)
+	}
+	expectBalance(t, s, "ZAR", "5000", "0")
+	if status := private(t, s, "POST", "/api/1/stoporder", stop,
+		&resp); status == http.StatusOK {
+		t.Errorf("Expected error stopping a completed order")
+	}
+
+	var list struct{ Orders []orderResp }
+	private(t, s, "GET", "/api/1/listorders", url.Values{"pair": {"XBTZAR"}},
+		&list)
+	if len(list.Orders) != 1 || list.Orders[0].OrderId != id ||
+		list.Orders[0].State != "COMPLETE" {
+		t.Errorf("Expected our completed order, got %+v", list.Orders)
+	}
+}
+
+func TestTakerOrder(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("XBT", amount("2"))
+
+	s.PlaceOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.BID, amount("1"), amount("4000"))
+	o := getOrder(t, s, postOrder(t, s, "ASK", "1.5", "3000").OrderId)
+	if o.State != "COMPLETE" || o.Counter != "7000" {
+		t.Errorf("Expected filled order, got %+v", o)
+	}
+	expectBalance(t, s, "XBT", "0.5", "0")
+	expectBalance(t, s, "ZAR", "7000", "0")
+
+	var trades struct{ Trades []struct{ Price string } }
+	call(t, s, "", "", "GET", "/api/1/trades",
+		url.Values{"pair": {"XBTZAR"}}, &trades)
+	if len(trades.Trades) != 2 || trades.Trades[0].Price != "4000" ||
+		trades.Trades[1].Price != "5000" {
+		t.Errorf("Expected two trades, most recent first, got %+v", trades)
+	}
+}
+
+func TestInsufficientBalance(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", amount("100"))
+
+	if o := postOrder(t, s, "BID", "1", "5000"); o.Error == "" {
+		t.Errorf("Expected error for insufficient balance")
+	}
+	send := func(amount string) int {
+		var resp map[string]interface{}
+		return private(t, s, "POST", "/api/1/send", url.Values{
+			"amount": {amount}, "currency": {"ZAR"}, "address": {"addr"},
+		}, &resp)
+	}
+	if send("200") == http.StatusOK {
+		t.Errorf("Expected error for insufficient balance")
+	}
+	if status := send("50"); status != http.StatusOK {
+		t.Fatalf("Expected send to succeed, got %d", status)
+	}
+	expectBalance(t, s, "ZAR", "50", "0")
+	if sends := s.Sends(); len(sends) != 1 || sends[0].Address != "addr" {
+		t.Errorf("Expected one send, got %v", sends)
+	}
+}
+
+func TestPlaceOrderInvalid(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	for _, test := range []struct {
+		pair          string
+		typ           bitx.OrderType
+		volume, price bitx.Amount
+	}{
+		{"XBT", bitx.BID, bitx.Unit, 5000 * bitx.Unit},
+		{"xbtzar", bitx.BID, bitx.Unit, 5000 * bitx.Unit},
+		{"XBTZAR", "BUY", bitx.Unit, 5000 * bitx.Unit},
+		{"XBTZAR", bitx.ASK, 0, 5000 * bitx.Unit},
+		{"XBTZAR", bitx.ASK, bitx.Unit, 0},
+	} {
+		if _, err := s.PlaceOrder(test.pair, test.typ, test.volume,
+			test.price); err == nil {
+			t.Errorf("Expected error for %+v", test)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", amount("10000"))
+
+	c := s.Client(bitx.WithUserAgent("bitxtest"))
+	id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := getOrder(t, s, id); o.State != "PENDING" {
+		t.Errorf("Expected pending order, got %+v", o)
+	}
+	balance, reserved, err := c.Balance("ZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != amount("10000") || reserved != amount("5000") {
+		t.Errorf("Expected balance 10000 (reserved 5000), got %s (reserved %s)",
+			balance, reserved)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetBalance("XBT", amount("2"))
+	s.SetBalance("ZAR", amount("10000"))
+	s.SetUnconfirmed("XBT", amount("0.1"))
+	c := s.Client()
+
+	if _, err := c.PostOrder("XBTZAR", bitx.ASK, amount("0.5"),
+		amount("6000")); err != nil {
+		t.Fatal(err)
+	}
+
+	bl, err := c.Balances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bitx.Balance{
+		{AccountId: s.AccountID("XBT"), Asset: "XBT", Balance: amount("2"),
+			Reserved: amount("0.5"), Unconfirmed: amount("0.1")},
+		{AccountId: s.AccountID("ZAR"), Asset: "ZAR",
+			Balance: amount("10000")},
+	}
+	if len(bl) != len(want) {
+		t.Fatalf("Expected %d balances, got %+v", len(want), bl)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(bl[i], want[i]) {
+			t.Errorf("Expected %+v, got %+v", want[i], bl[i])
+		}
+	}
+	if bl[0].AccountId == bl[1].AccountId {
+		t.Errorf("Expected separate accounts, got %+v", bl)
+	}
+
+	bl, err = c.Balances("ZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bl) != 1 || !reflect.DeepEqual(bl[0], want[1]) {
+		t.Errorf("Expected only %+v, got %+v", want[1], bl)
+	}
+
+	bl, err = c.Balances("ZAR", "XBT", "ETH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bl) != 2 {
+		t.Errorf("Expected XBT and ZAR balances, got %+v", bl)
+	}
+}
@@ -0,0 +1,158 @@
+package bitx
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// ErrNotStopped indicates that the API didn't stop an order.
+var ErrNotStopped = errors.New("order was not stopped")
+
+// ErrOrdersRemain indicates that pending orders were still listed after
+// CancelAll stopped the orders it found, e.g. because more were placed
+// meanwhile.
+var ErrOrdersRemain = errors.New("pending orders remain")
+
+// ErrOrderNotPending indicates that an order can't be replaced because it
+// is no longer pending.
+var ErrOrderNotPending = errors.New("order is not pending")
+
+// CancelResult is the outcome of stopping one order.
+type CancelResult struct {
+	Order Order
+	// Err is nil if the order was stopped.
+	Err error
+}
+
+// Stops all pending orders in a currency pair, or only those of the given
+// types, e.g. BID. The orders are stopped concurrently, waiting for the
+// client's rate limiter as usual, and a result is returned for each order
+// in the order they were listed. The orders are listed again afterwards and
+// ErrOrdersRemain is returned along with the results if any are still
+// pending. Any other error means the orders could not be listed; check each
+// result for failures to stop.
+func (c *Client) CancelAll(pair string, types ...OrderType) (
+	[]CancelResult, error) {
+	return c.CancelAllContext(context.Background(), pair, types...)
+}
+
+// CancelAllContext is like CancelAll but takes a context.
+func (c *Client) CancelAllContext(ctx context.Context, pair string,
+	types ...OrderType) ([]CancelResult, error) {
+	orders, err := c.pendingOrders(ctx, pair, types)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]CancelResult, len(orders))
+	for i, o := range orders {
+		results[i].Order = o
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentRequests)
+	for i := range results {
+		r := &results[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r.Err = c.StopOrderContext(ctx, r.Order.Id)
+		}()
+	}
+	wg.Wait()
+
+	orders, err = c.pendingOrders(ctx, pair, types)
+	if err != nil {
+		return results, err
+	}
+	if len(orders) > 0 {
+		return results, ErrOrdersRemain
+	}
+	return results, nil
+}
+
+// pendingOrders lists the pending orders in pair of the given types.
+func (c *Client) pendingOrders(ctx context.Context, pair string,
+	types []OrderType) ([]Order, error) {
+	var orders []Order
+	it := c.Orders(ctx, ListOrdersOptions{Pair: pair, State: Pending})
+	for it.Next() {
+		if o := it.Order(); matchesType(o.Type, types) {
+			orders = append(orders, o)
+		}
+	}
+	return orders, it.Err()
+}
+
+func matchesType(t OrderType, types []OrderType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, want := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceResult is the outcome of CancelReplace.
+type ReplaceResult struct {
+	// Stopped is the old order after it was stopped.
+	Stopped Order
+	// Filled is the base volume of the old order which traded after
+	// CancelReplace fetched it and before it was stopped.
+	Filled Amount
+	// NewOrderId is the id of the replacement order, or empty if it
+	// wasn't placed.
+	NewOrderId string
+}
+
+// Stops a pending limit order and places an order of the same type and pair
+// for volume at price in its place. The old order may fill partly before it
+// is stopped: the result reports how much, so that the caller can adjust
+// the volume it requotes next time. The replacement isn't placed if the old
+// order can't be stopped, in which case both the result, if the old order's
+// state is known, and the error are returned.
+func (c *Client) CancelReplace(id string, volume, price Amount) (
+	*ReplaceResult, error) {
+	return c.CancelReplaceContext(context.Background(), id, volume, price)
+}
+
+// CancelReplaceContext is like CancelReplace but takes a context.
+func (c *Client) CancelReplaceContext(ctx context.Context, id string,
+	volume, price Amount) (*ReplaceResult, error) {
+	before, err := c.GetOrderContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.State != Pending {
+		return &ReplaceResult{Stopped: *before}, ErrOrderNotPending
+	}
+
+	stopErr := c.StopOrderContext(ctx, id)
+	after, err := c.GetOrderContext(ctx, id)
+	if err != nil {
+		if stopErr != nil {
+			return nil, stopErr
+		}
+		return nil, err
+	}
+	res := &ReplaceResult{Stopped: *after, Filled: after.Base - before.Base}
+	if stopErr != nil {
+		return res, stopErr
+	}
+	if after.State == Pending {
+		return res, ErrNotStopped
+	}
+
+	res.NewOrderId, err = c.PostOrderContext(ctx, after.Pair, after.Type,
+		volume, price)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
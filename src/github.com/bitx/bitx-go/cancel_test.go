@@ -0,0 +1,133 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestCancelAll(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000", "XBT", "10")
+
+	var bids []string
+	for _, price := range []string{"100", "101", "102"} {
+		id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount(price))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bids = append(bids, id)
+	}
+	ask, err := c.PostOrder("XBTZAR", bitx.ASK, amount("1"), amount("200"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := c.CancelAll("XBTZAR", bitx.BID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(bids) {
+		t.Fatalf("Expected %d results, got %+v", len(bids), results)
+	}
+	for i, r := range results {
+		if want := bids[len(bids)-1-i]; r.Order.Id != want || r.Err != nil {
+			t.Errorf("Expected %s to be stopped, got %+v", want, r)
+		}
+	}
+	expectBalance(t, s, "ZAR", "10000", "0")
+	expectBalance(t, s, "XBT", "10", "1")
+
+	results, err = c.CancelAll("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Order.Id != ask ||
+		results[0].Err != nil {
+		t.Errorf("Expected ask %s to be stopped, got %+v", ask, results)
+	}
+	expectBalance(t, s, "XBT", "10", "0")
+}
+
+func TestCancelAllOrdersRemain(t *testing.T) {
+	s, _ := newFake(t, "ZAR", "10000")
+
+	// Another bid is placed while the first is being stopped.
+	other := s.Client()
+	var placed string
+	placeOnStop := func(ctx context.Context, req *bitx.Request,
+		invoke bitx.Invoker) error {
+		if req.Path == "/api/1/stoporder" && placed == "" {
+			var err error
+			placed, err = other.PostOrder("XBTZAR", bitx.BID, amount("1"),
+				amount("90"))
+			if err != nil {
+				return err
+			}
+		}
+		return invoke(ctx, req)
+	}
+	c := s.Client(bitx.WithInterceptors(placeOnStop))
+
+	id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := c.CancelAll("XBTZAR")
+	if err != bitx.ErrOrdersRemain {
+		t.Errorf("Expected %v, got %v", bitx.ErrOrdersRemain, err)
+	}
+	if len(results) != 1 || results[0].Order.Id != id ||
+		results[0].Err != nil {
+		t.Errorf("Expected %s to be stopped, got %+v", id, results)
+	}
+	o, err := c.GetOrder(placed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.State != bitx.Pending {
+		t.Errorf("Expected %s to remain pending, got %s", placed, o.State)
+	}
+}
+
+func TestCancelReplace(t *testing.T) {
+	s, _ := newFake(t, "ZAR", "10000")
+
+	// Another participant sells into the bid just before it is stopped.
+	fillFirst := func(ctx context.Context, req *bitx.Request,
+		invoke bitx.Invoker) error {
+		if req.Path == "/api/1/stoporder" {
+			s.PlaceOrder("XBTZAR", bitx.ASK, amount("0.4"), amount("100"))
+		}
+		return invoke(ctx, req)
+	}
+	c := s.Client(bitx.WithInterceptors(fillFirst))
+
+	id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.CancelReplace(id, amount("0.6"), amount("110"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Filled != amount("0.4") || res.Stopped.State != bitx.Complete ||
+		res.NewOrderId == "" {
+		t.Errorf("Unexpected result %+v", res)
+	}
+	o, err := c.GetOrder(res.NewOrderId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Type != bitx.BID || o.LimitVolume != amount("0.6") ||
+		o.LimitPrice != amount("110") || o.State != bitx.Pending {
+		t.Errorf("Unexpected replacement %+v", o)
+	}
+	expectBalance(t, s, "ZAR", "9960", "66")
+
+	if _, err := c.CancelReplace(id, amount("1"), amount("100")); err !=
+		bitx.ErrOrderNotPending {
+		t.Errorf("Expected %v, got %v", bitx.ErrOrderNotPending, err)
+	}
+}
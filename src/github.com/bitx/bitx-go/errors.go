@@ -0,0 +1,96 @@
+package bitx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiResponse holds the error fields common to all API responses.
+type apiResponse struct {
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"`
+
+	// raw is the response body if the client keeps raw JSON.
+	raw json.RawMessage
+}
+
+func (r *apiResponse) response() *apiResponse {
+	return r
+}
+
+// errorResponse is implemented by all response types which embed
+// apiResponse.
+type errorResponse interface {
+	response() *apiResponse
+}
+
+// APIError is an error returned by the BitX API.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the API's error code, e.g. "ErrInsufficientBalance". It is
+	// empty if the API didn't return one.
+	Code string
+
+	// Message describes the error.
+	Message string
+
+	// Retryable is true if the request was not processed and may safely be
+	// sent again, e.g. because it was rate limited.
+	Retryable bool
+
+	// RetryAfter is how long the API asked us to wait before retrying, or
+	// zero if it didn't say.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("BitX error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("BitX error %d: %s: %s", e.StatusCode, e.Code,
+		e.Message)
+}
+
+// IsRetryable returns true if err is an *APIError which may safely be
+// retried.
+func IsRetryable(err error) bool {
+	e, ok := err.(*APIError)
+	return ok && e.Retryable
+}
+
+// newAPIError builds an error from a non-200 response.
+func newAPIError(method string, r *http.Response) *APIError {
+	e := &APIError{StatusCode: r.StatusCode, Message: r.Status}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Error != "" {
+		e.Code = resp.ErrorCode
+		e.Message = resp.Error
+	} else if b := strings.TrimSpace(string(body)); b != "" {
+		e.Message = r.Status + ": " + b
+	}
+
+	if secs, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(secs) * time.Second
+	}
+
+	switch {
+	case r.StatusCode == http.StatusTooManyRequests:
+		// Rate limited requests are rejected before being processed.
+		e.Retryable = true
+	case r.StatusCode >= 500:
+		// A server error may occur after a POST, PUT or DELETE has
+		// taken effect.
+		e.Retryable = method == "GET"
+	}
+
+	return e
+}
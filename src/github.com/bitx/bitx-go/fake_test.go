@@ -0,0 +1,35 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"github.com/bitx/bitx-go"
+	"github.com/bitx/bitx-go/bitxtest"
+)
+
+var amount = bitx.MustParseAmount
+
+// newFake starts a fake exchange holding the given balances, as pairs of
+// asset and amount, e.g. newFake(t, "ZAR", "10000"), and returns it with a
+// client for it. The exchange is closed when the test finishes.
+func newFake(t *testing.T, balances ...string) (*bitxtest.Server,
+	*bitx.Client) {
+	if len(balances)%2 != 0 {
+		t.Fatalf("newFake: odd number of balance arguments %v", balances)
+	}
+	s := bitxtest.NewServer()
+	t.Cleanup(s.Close)
+	for i := 0; i < len(balances); i += 2 {
+		s.SetBalance(balances[i], amount(balances[i+1]))
+	}
+	return s, s.Client()
+}
+
+func expectBalance(t *testing.T, s *bitxtest.Server, asset string,
+	balance, reserved string) {
+	b, r := s.Balance(asset)
+	if b != amount(balance) || r != amount(reserved) {
+		t.Errorf("Expected %s balance %s (reserved %s), got %s (reserved %s)",
+			asset, balance, reserved, b, r)
+	}
+}
@@ -0,0 +1,122 @@
+package bitx
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"golang.org/x/net/context"
+)
+
+type feeInfo struct {
+	apiResponse
+	MakerFee        string `json:"maker_fee"`
+	TakerFee        string `json:"taker_fee"`
+	ThirtyDayVolume string `json:"thirty_day_volume"`
+}
+
+// FeeInfo holds the user's trading fees for a pair.
+type FeeInfo struct {
+	// MakerFee and TakerFee are fee rates, e.g. 0.001 for 0.1%. The maker
+	// fee is charged on fills of orders resting in the order book and the
+	// taker fee on fills of orders which cross the spread.
+	MakerFee, TakerFee Amount
+	// ThirtyDayVolume is the user's trading volume in the base currency
+	// over the last 30 days, which determines the fee rates.
+	ThirtyDayVolume Amount
+	Raw             json.RawMessage // See WithRawJSON.
+}
+
+// Returns the user's fee rates and trading volume for a currency pair.
+func (c *Client) FeeInfo(pair string) (*FeeInfo, error) {
+	return c.FeeInfoContext(context.Background(), pair)
+}
+
+// FeeInfoContext is like FeeInfo but takes a context.
+func (c *Client) FeeInfoContext(ctx context.Context, pair string) (
+	*FeeInfo, error) {
+	var r feeInfo
+	err := c.call(ctx, "GET", "/api/1/fee_info", url.Values{"pair": {pair}}, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	fi := &FeeInfo{
+		MakerFee:        p.amount("maker_fee", r.MakerFee),
+		TakerFee:        p.amount("taker_fee", r.TakerFee),
+		ThirtyDayVolume: p.amount("thirty_day_volume", r.ThirtyDayVolume),
+		Raw:             r.raw,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return fi, nil
+}
+
+// OrderPreview is the expected outcome of a limit order which fills
+// completely at its limit price.
+type OrderPreview struct {
+	Type OrderType
+	// Maker is true if the order would rest in the order book and pay the
+	// maker fee rather than the taker fee.
+	Maker   bool
+	FeeRate Amount
+	// Cost is the amount spent: counter currency for a BID and base
+	// currency for an ASK.
+	Cost Amount
+	// Fee is charged in the currency received: base currency for a BID and
+	// counter currency for an ASK.
+	Fee Amount
+	// Proceeds is the amount received after the fee.
+	Proceeds Amount
+}
+
+// Preview returns the outcome of an order to trade volume at price if it
+// fills completely at its limit price. maker selects the fee rate.
+func (f *FeeInfo) Preview(order_type OrderType, volume, price Amount,
+	maker bool) OrderPreview {
+	rate := f.TakerFee
+	if maker {
+		rate = f.MakerFee
+	}
+	pr := OrderPreview{Type: order_type, Maker: maker, FeeRate: rate}
+	counter := volume.Mul(price)
+	if order_type == BID {
+		pr.Cost, pr.Fee = counter, volume.Mul(rate)
+		pr.Proceeds = volume - pr.Fee
+	} else {
+		pr.Cost, pr.Fee = volume, counter.Mul(rate)
+		pr.Proceeds = counter - pr.Fee
+	}
+	return pr
+}
+
+// Previews the outcome of PostOrder with the same arguments using the
+// user's current fee rates. The order is assumed to pay the taker fee if
+// its price crosses the current best bid or ask. A taker order may fill at
+// better prices than the preview assumes.
+func (c *Client) PreviewOrder(pair string, order_type OrderType,
+	volume, price Amount) (*OrderPreview, error) {
+	return c.PreviewOrderContext(context.Background(), pair, order_type,
+		volume, price)
+}
+
+// PreviewOrderContext is like PreviewOrder but takes a context.
+func (c *Client) PreviewOrderContext(ctx context.Context, pair string,
+	order_type OrderType, volume, price Amount) (*OrderPreview, error) {
+	fi, err := c.FeeInfoContext(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+	t, err := c.TickerContext(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+
+	maker := price < t.Ask || t.Ask == 0
+	if order_type == ASK {
+		maker = price > t.Bid
+	}
+	pr := fi.Preview(order_type, volume, price, maker)
+	return &pr, nil
+}
@@ -0,0 +1,29 @@
+package bitx
+
+import "testing"
+
+func TestPreview(t *testing.T) {
+	fi := &FeeInfo{MakerFee: MustParseAmount("0.001"),
+		TakerFee: MustParseAmount("0.0025")}
+	for _, test := range []struct {
+		typ   OrderType
+		maker bool
+		want  OrderPreview
+	}{
+		{BID, true, OrderPreview{Cost: MustParseAmount("20000"),
+			Fee: MustParseAmount("0.002"), Proceeds: MustParseAmount("1.998")}},
+		{BID, false, OrderPreview{Cost: MustParseAmount("20000"),
+			Fee: MustParseAmount("0.005"), Proceeds: MustParseAmount("1.995")}},
+		{ASK, true, OrderPreview{Cost: MustParseAmount("2"),
+			Fee: MustParseAmount("20"), Proceeds: MustParseAmount("19980")}},
+		{ASK, false, OrderPreview{Cost: MustParseAmount("2"),
+			Fee: MustParseAmount("50"), Proceeds: MustParseAmount("19950")}},
+	} {
+		pr := fi.Preview(test.typ, 2*Unit, 10000*Unit, test.maker)
+		if pr.Cost != test.want.Cost || pr.Fee != test.want.Fee ||
+			pr.Proceeds != test.want.Proceeds || pr.Maker != test.maker {
+			t.Errorf("%s maker=%v: expected %+v, got %+v",
+				test.typ, test.maker, test.want, pr)
+		}
+	}
+}
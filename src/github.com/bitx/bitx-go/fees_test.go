@@ -0,0 +1,55 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestFees(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+	s.SetFees(amount("0"), amount("0.01"))
+
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.BID, amount("1"), amount("4000"))
+
+	pr, err := c.PreviewOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bitx.OrderPreview{Type: bitx.BID, FeeRate: amount("0.01"),
+		Cost: amount("5000"), Fee: amount("0.01"), Proceeds: amount("0.99")}
+	if *pr != want {
+		t.Errorf("Expected taker preview %+v, got %+v", want, *pr)
+	}
+
+	id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := c.GetOrder(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.FeeBase != pr.Fee || o.FeeCounter != 0 {
+		t.Errorf("Expected fee of %s XBT, got %+v", pr.Fee, o)
+	}
+	expectBalance(t, s, "XBT", "0.99", "0")
+
+	pr, err = c.PreviewOrder("XBTZAR", bitx.ASK, amount("0.5"), amount("4500"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pr.Maker || pr.Fee != 0 || pr.Proceeds != amount("2250") {
+		t.Errorf("Expected maker preview without fee, got %+v", pr)
+	}
+
+	fi, err := c.FeeInfo("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.MakerFee != 0 || fi.TakerFee != amount("0.01") ||
+		fi.ThirtyDayVolume != amount("1") {
+		t.Errorf("Expected fees and volume of 1 XBT, got %+v", fi)
+	}
+}
@@ -0,0 +1,213 @@
+package bitx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type fundingAddress struct {
+	apiResponse
+	Asset            string `json:"asset"`
+	Address          string `json:"address"`
+	TotalReceived    string `json:"total_received"`
+	TotalUnconfirmed string `json:"total_unconfirmed"`
+}
+
+// FundingAddress is an address which receives funds into an account.
+type FundingAddress struct {
+	Asset   string
+	Address string
+	// TotalReceived is the total confirmed amount received at the address
+	// and TotalUnconfirmed the amount awaiting confirmation.
+	TotalReceived, TotalUnconfirmed Amount
+	Raw                             json.RawMessage // See WithRawJSON.
+}
+
+func (c *Client) fundingAddress(ctx context.Context, method, asset string) (
+	*FundingAddress, error) {
+	var r fundingAddress
+	err := c.call(ctx, method, "/api/1/funding_address",
+		url.Values{"asset": {asset}}, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	fa := &FundingAddress{
+		Asset:            r.Asset,
+		Address:          r.Address,
+		TotalReceived:    p.amount("total_received", r.TotalReceived),
+		TotalUnconfirmed: p.amount("total_unconfirmed", r.TotalUnconfirmed),
+		Raw:              r.raw,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return fa, nil
+}
+
+// Returns the default receive address for an asset, e.g. XBT.
+func (c *Client) FundingAddress(asset string) (*FundingAddress, error) {
+	return c.FundingAddressContext(context.Background(), asset)
+}
+
+// FundingAddressContext is like FundingAddress but takes a context.
+func (c *Client) FundingAddressContext(ctx context.Context, asset string) (
+	*FundingAddress, error) {
+	return c.fundingAddress(ctx, "GET", asset)
+}
+
+// Creates a new receive address for an asset.
+func (c *Client) CreateFundingAddress(asset string) (*FundingAddress, error) {
+	return c.CreateFundingAddressContext(context.Background(), asset)
+}
+
+// CreateFundingAddressContext is like CreateFundingAddress but takes a
+// context.
+func (c *Client) CreateFundingAddressContext(ctx context.Context,
+	asset string) (*FundingAddress, error) {
+	return c.fundingAddress(ctx, "POST", asset)
+}
+
+// WithdrawalStatus is the status of a withdrawal request. Statuses not
+// listed below are kept verbatim.
+type WithdrawalStatus string
+
+const WithdrawalPending = WithdrawalStatus("PENDING")
+const WithdrawalProcessing = WithdrawalStatus("PROCESSING")
+const WithdrawalCompleted = WithdrawalStatus("COMPLETED")
+const WithdrawalCancelled = WithdrawalStatus("CANCELLED")
+
+type withdrawal struct {
+	apiResponse
+	Id        string `json:"id"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+	Type      string `json:"type"`
+	Currency  string `json:"currency"`
+	Amount    string `json:"amount"`
+	Fee       string `json:"fee"`
+}
+
+type withdrawals struct {
+	apiResponse
+	Withdrawals []withdrawal `json:"withdrawals"`
+}
+
+// Withdrawal is a request to withdraw funds to a linked bank account.
+type Withdrawal struct {
+	Id        string
+	Status    WithdrawalStatus
+	CreatedAt time.Time
+	// Type is the kind of withdrawal, e.g. ZAR_EFT.
+	Type        string
+	Currency    string
+	Amount, Fee Amount
+	Raw         json.RawMessage // See WithRawJSON.
+}
+
+// parseWithdrawal converts a withdrawal. prefix is the path of the
+// withdrawal in the response, e.g. "withdrawals[2].", for error messages.
+func parseWithdrawal(p *parser, prefix string, bw withdrawal) Withdrawal {
+	return Withdrawal{
+		Id:        bw.Id,
+		Status:    WithdrawalStatus(bw.Status),
+		CreatedAt: millis(bw.CreatedAt),
+		Type:      bw.Type,
+		Currency:  bw.Currency,
+		Amount:    p.amount(prefix+"amount", bw.Amount),
+		Fee:       p.amount(prefix+"fee", bw.Fee),
+	}
+}
+
+func (c *Client) withdrawal(ctx context.Context, method, path string,
+	params url.Values) (*Withdrawal, error) {
+	var r withdrawal
+	err := c.call(ctx, method, path, params, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	w := parseWithdrawal(p, "", r)
+	w.Raw = r.raw
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &w, nil
+}
+
+// Returns the account's withdrawal requests, most recent first.
+func (c *Client) ListWithdrawals() ([]Withdrawal, error) {
+	return c.ListWithdrawalsContext(context.Background())
+}
+
+// ListWithdrawalsContext is like ListWithdrawals but takes a context.
+func (c *Client) ListWithdrawalsContext(ctx context.Context) (
+	[]Withdrawal, error) {
+	var r withdrawals
+	err := c.call(ctx, "GET", "/api/1/withdrawals", nil, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	raws := r.rawList("withdrawals")
+	ws := make([]Withdrawal, len(r.Withdrawals))
+	for i, bw := range r.Withdrawals {
+		ws[i] = parseWithdrawal(p, index("withdrawals", i)+".", bw)
+		ws[i].Raw = rawAt(raws, i)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return ws, nil
+}
+
+// Requests a withdrawal of amount to the default linked bank account for
+// the withdrawal type, e.g. ZAR_EFT.
+func (c *Client) RequestWithdrawal(typ string, amount Amount) (
+	*Withdrawal, error) {
+	return c.RequestWithdrawalContext(context.Background(), typ, amount)
+}
+
+// RequestWithdrawalContext is like RequestWithdrawal but takes a context.
+func (c *Client) RequestWithdrawalContext(ctx context.Context, typ string,
+	amount Amount) (*Withdrawal, error) {
+	form := make(url.Values)
+	form.Add("type", typ)
+	form.Add("amount", amount.String())
+	return c.withdrawal(ctx, "POST", "/api/1/withdrawals", form)
+}
+
+// Get a withdrawal request by its id.
+func (c *Client) GetWithdrawal(id string) (*Withdrawal, error) {
+	return c.GetWithdrawalContext(context.Background(), id)
+}
+
+// GetWithdrawalContext is like GetWithdrawal but takes a context.
+func (c *Client) GetWithdrawalContext(ctx context.Context, id string) (
+	*Withdrawal, error) {
+	if !isValidPathID(id) {
+		return nil, errors.New("invalid withdrawal id")
+	}
+	return c.withdrawal(ctx, "GET", "/api/1/withdrawals/"+id, nil)
+}
+
+// Cancels a pending withdrawal request and returns it.
+func (c *Client) CancelWithdrawal(id string) (*Withdrawal, error) {
+	return c.CancelWithdrawalContext(context.Background(), id)
+}
+
+// CancelWithdrawalContext is like CancelWithdrawal but takes a context.
+func (c *Client) CancelWithdrawalContext(ctx context.Context, id string) (
+	*Withdrawal, error) {
+	if !isValidPathID(id) {
+		return nil, errors.New("invalid withdrawal id")
+	}
+	return c.withdrawal(ctx, "DELETE", "/api/1/withdrawals/"+id, nil)
+}
@@ -0,0 +1,91 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestFundingAddress(t *testing.T) {
+	s, c := newFake(t)
+
+	fa, err := c.FundingAddress("XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fa.Asset != "XBT" || fa.Address == "" {
+		t.Fatalf("Expected XBT address, got %+v", fa)
+	}
+	s.Receive(fa.Address, amount("0.25"))
+
+	again, err := c.FundingAddress("XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Address != fa.Address || again.TotalReceived != amount("0.25") {
+		t.Errorf("Expected default address to have received 0.25, got %+v",
+			again)
+	}
+	expectBalance(t, s, "XBT", "0.25", "0")
+
+	created, err := c.CreateFundingAddress("XBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Address == fa.Address || created.TotalReceived != 0 {
+		t.Errorf("Expected new empty address, got %+v", created)
+	}
+}
+
+func TestWithdrawals(t *testing.T) {
+	s, c := newFake(t, "ZAR", "1000")
+
+	if _, err := c.RequestWithdrawal("ZAR_EFT", amount("2000")); err == nil {
+		t.Errorf("Expected error for insufficient balance")
+	}
+	first, err := c.RequestWithdrawal("ZAR_EFT", amount("300"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != bitx.WithdrawalPending || first.Currency != "ZAR" ||
+		first.Amount != amount("300") {
+		t.Errorf("Expected pending withdrawal of 300 ZAR, got %+v", first)
+	}
+	second, err := c.RequestWithdrawal("ZAR_EFT", amount("200"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectBalance(t, s, "ZAR", "1000", "500")
+
+	cancelled, err := c.CancelWithdrawal(second.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Status != bitx.WithdrawalCancelled {
+		t.Errorf("Expected cancelled withdrawal, got %+v", cancelled)
+	}
+	if _, err := c.CancelWithdrawal(second.Id); err == nil {
+		t.Errorf("Expected error cancelling twice")
+	}
+
+	s.CompleteWithdrawal(first.Id)
+	w, err := c.GetWithdrawal(first.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != bitx.WithdrawalCompleted {
+		t.Errorf("Expected completed withdrawal, got %+v", w)
+	}
+	expectBalance(t, s, "ZAR", "700", "0")
+
+	ws, err := c.ListWithdrawals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ws) != 2 || ws[0].Id != second.Id || ws[1].Id != first.Id {
+		t.Errorf("Expected both withdrawals, most recent first, got %+v", ws)
+	}
+	if _, err := c.GetWithdrawal("999"); err == nil {
+		t.Errorf("Expected error for unknown withdrawal")
+	}
+}
@@ -0,0 +1,101 @@
+package bitx
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Request is an API request passed to interceptors. It doesn't include the
+// client's credentials, which are added when the request is sent.
+type Request struct {
+	Method string
+	// Path is the API path, e.g. /api/1/ticker.
+	Path string
+	// Params are sent in the query string of GET and DELETE requests and
+	// in the body of POST and PUT requests.
+	Params url.Values
+	// Header holds extra headers to send, e.g. a request ID. They replace
+	// the client's own headers of the same name, like User-Agent.
+	Header http.Header
+	// Attempt counts retries of the request, starting from 0.
+	Attempt int
+	// Result is the value the response is decoded into.
+	Result interface{}
+}
+
+// Invoker sends a request, or passes it to the next interceptor.
+type Invoker func(ctx context.Context, req *Request) error
+
+// Interceptor is called for each attempt at an API request, after waiting
+// for the rate limiter. It may inspect or modify the request before calling
+// invoke to send it, and inspect the result or error afterwards. It may also
+// return an error without calling invoke; the error is retried like one
+// from the API.
+type Interceptor func(ctx context.Context, req *Request, invoke Invoker) error
+
+// WithInterceptors adds interceptors to the client. The first interceptor is
+// the outermost: it is called first and sees the error returned by the rest
+// of the chain.
+func WithInterceptors(is ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, is...)
+	}
+}
+
+// invoke sends a request through the client's interceptors. transient is
+// true if the request reached the network and failed before a response was
+// received.
+func (c *Client) invoke(ctx context.Context, req *Request) (
+	transient bool, err error) {
+	send := func(ctx context.Context, req *Request) error {
+		var err error
+		transient, err = c.do(ctx, req)
+		return err
+	}
+	chain := send
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		ic, next := c.interceptors[i], chain
+		chain = func(ctx context.Context, req *Request) error {
+			return ic(ctx, req, next)
+		}
+	}
+	err = chain(ctx, req)
+	return transient, err
+}
+
+// String formats the method, path and parameters of the request, e.g.
+// "GET /api/1/ticker pair=XBTZAR". It is safe to log since requests don't
+// include credentials.
+func (r *Request) String() string {
+	s := r.Method + " " + r.Path
+	keys := make([]string, 0, len(r.Params))
+	for k := range r.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s += " " + k + "=" + strings.Join(r.Params[k], ",")
+	}
+	return s
+}
+
+// LoggingInterceptor returns an interceptor which logs each request with its
+// latency and error using logf, e.g. log.Printf.
+func LoggingInterceptor(logf func(format string, args ...interface{})) Interceptor {
+	return func(ctx context.Context, req *Request, invoke Invoker) error {
+		start := time.Now()
+		err := invoke(ctx, req)
+		d := time.Since(start)
+		if err != nil {
+			logf("bitx: %s (attempt %d): %v: %v", req, req.Attempt, d, err)
+		} else {
+			logf("bitx: %s (attempt %d): %v", req, req.Attempt, d)
+		}
+		return err
+	}
+}
@@ -0,0 +1,120 @@
+package bitx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	var gotID, gotAuth string
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get("X-Request-Id")
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"bid":"1","ask":"2","last_trade":"1",` +
+			`"rolling_24_hour_volume":"1"}`))
+	})
+	defer s.Close()
+
+	var calls []string
+	tag := func(name string) Interceptor {
+		return func(ctx context.Context, req *Request, invoke Invoker) error {
+			calls = append(calls, name)
+			req.Header.Set("X-Request-Id", name)
+			return invoke(ctx, req)
+		}
+	}
+	c := NewClient("id", "secret", withBase,
+		WithInterceptors(tag("a")), WithInterceptors(tag("b")))
+	if _, err := c.Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "a,b" {
+		t.Errorf("Expected interceptors a then b, got %v", calls)
+	}
+	if gotID != "b" {
+		t.Errorf("Expected request ID b, got %q", gotID)
+	}
+	if gotAuth == "" {
+		t.Errorf("Expected credentials to be sent")
+	}
+}
+
+func TestInterceptorRetry(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 0, http.StatusOK)
+	defer stop()
+
+	var attempts []int
+	fail := func(ctx context.Context, req *Request, invoke Invoker) error {
+		attempts = append(attempts, req.Attempt)
+		if req.Attempt == 0 {
+			return &APIError{StatusCode: 503, Retryable: true}
+		}
+		return invoke(ctx, req)
+	}
+	c := NewClient("", "", withBase, WithRetry(2, time.Millisecond),
+		WithInterceptors(fail))
+	if _, err := c.Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[1] != 1 || *requests != 1 {
+		t.Errorf("Expected one failed and one sent attempt, got %v and "+
+			"%d requests", attempts, *requests)
+	}
+
+	wantErr := errors.New("down")
+	c = NewClient("", "", withBase, WithInterceptors(
+		func(ctx context.Context, req *Request, invoke Invoker) error {
+			return wantErr
+		}))
+	if _, err := c.Ticker("XBTZAR"); err != wantErr {
+		t.Errorf("Expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	_, withBase, stop := flakyServer(t, 0, http.StatusOK)
+	defer stop()
+
+	var lines []string
+	logf := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	c := NewClient("id", "secret", withBase,
+		WithInterceptors(LoggingInterceptor(logf)))
+	if _, err := c.Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 ||
+		!strings.HasPrefix(lines[0], "bitx: GET /api/1/ticker pair=XBTZAR") ||
+		strings.Contains(lines[0], "secret") {
+		t.Errorf("Unexpected log %q", lines)
+	}
+}
+
+func TestInterceptorHeaderReplacesDefault(t *testing.T) {
+	var got []string
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header["User-Agent"]
+		w.Write([]byte(`{"bid":"1","ask":"2","last_trade":"1",` +
+			`"rolling_24_hour_volume":"1"}`))
+	})
+	defer s.Close()
+
+	ua := func(ctx context.Context, req *Request, invoke Invoker) error {
+		req.Header.Set("User-Agent", "bot/1.0")
+		return invoke(ctx, req)
+	}
+	c := NewClient("", "", withBase, WithInterceptors(ua))
+	if _, err := c.Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "bot/1.0" {
+		t.Errorf("Expected one User-Agent bot/1.0, got %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+package bitx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/context"
+)
+
+// MarketStatus is the trading status of a market. Statuses not listed below
+// are kept verbatim.
+type MarketStatus string
+
+// Active markets accept all orders.
+const Active = MarketStatus("ACTIVE")
+
+// PostOnly markets only accept orders which don't trade immediately.
+const PostOnly = MarketStatus("POSTONLY")
+
+// Disabled markets don't accept orders.
+const Disabled = MarketStatus("DISABLED")
+
+// ErrMarketNotActive indicates that a market isn't accepting orders.
+var ErrMarketNotActive = errors.New("market is not accepting orders")
+
+// ErrUnknownMarket indicates that the exchange doesn't list the pair.
+var ErrUnknownMarket = errors.New("unknown market")
+
+// OrderError indicates that an order's volume or price is outside the
+// market's limits.
+type OrderError struct {
+	Pair string
+	// Field is "volume" or "price".
+	Field    string
+	Value    Amount
+	Min, Max Amount
+}
+
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("bitx: %s %s %s is outside [%s, %s]",
+		e.Pair, e.Field, e.Value, e.Min, e.Max)
+}
+
+type market struct {
+	MarketId        string `json:"market_id"`
+	TradingStatus   string `json:"trading_status"`
+	BaseCurrency    string `json:"base_currency"`
+	CounterCurrency string `json:"counter_currency"`
+	MinVolume       string `json:"min_volume"`
+	MaxVolume       string `json:"max_volume"`
+	VolumeScale     int    `json:"volume_scale"`
+	MinPrice        string `json:"min_price"`
+	MaxPrice        string `json:"max_price"`
+	PriceScale      int    `json:"price_scale"`
+}
+
+type markets struct {
+	apiResponse
+	Markets []market `json:"markets"`
+}
+
+// Market describes the orders a currency pair accepts.
+type Market struct {
+	Pair                          string
+	Status                        MarketStatus
+	BaseCurrency, CounterCurrency string
+	// Order volumes must be within [MinVolume, MaxVolume] and have at most
+	// VolumeScale decimal places.
+	MinVolume, MaxVolume Amount
+	VolumeScale          int
+	// Order prices must be within [MinPrice, MaxPrice] and have at most
+	// PriceScale decimal places.
+	MinPrice, MaxPrice Amount
+	PriceScale         int
+	Raw                json.RawMessage // See WithRawJSON.
+}
+
+// tick returns the smallest amount with the given number of decimal places.
+func tick(scale int) Amount {
+	t := Unit
+	for i := 0; i < scale && t > 1; i++ {
+		t /= 10
+	}
+	return t
+}
+
+// VolumeTick returns the smallest step in order volume.
+func (m *Market) VolumeTick() Amount {
+	return tick(m.VolumeScale)
+}
+
+// PriceTick returns the smallest step in order price.
+func (m *Market) PriceTick() Amount {
+	return tick(m.PriceScale)
+}
+
+// ValidateOrder checks a limit order against the market's limits before it
+// is sent. It rounds the volume down to the volume tick, and the price to the
+// price tick away from the other side of the book: down for a BID and up for
+// an ASK. It returns the rounded volume and price, or ErrMarketNotActive or
+// an *OrderError if the market would reject the order.
+func (m *Market) ValidateOrder(order_type OrderType, volume, price Amount) (
+	Amount, Amount, error) {
+	if m.Status != Active && m.Status != PostOnly {
+		return 0, 0, ErrMarketNotActive
+	}
+
+	volume -= volume % m.VolumeTick()
+	t := m.PriceTick()
+	if r := price % t; r != 0 {
+		price -= r
+		if order_type == ASK {
+			price += t
+		}
+	}
+
+	if volume < m.MinVolume || volume > m.MaxVolume {
+		return 0, 0, &OrderError{Pair: m.Pair, Field: "volume", Value: volume,
+			Min: m.MinVolume, Max: m.MaxVolume}
+	}
+	if price < m.MinPrice || price > m.MaxPrice {
+		return 0, 0, &OrderError{Pair: m.Pair, Field: "price", Value: price,
+			Min: m.MinPrice, Max: m.MaxPrice}
+	}
+	return volume, price, nil
+}
+
+// Returns the markets listed by the exchange.
+func (c *Client) Markets() ([]Market, error) {
+	return c.MarketsContext(context.Background())
+}
+
+// MarketsContext is like Markets but takes a context.
+func (c *Client) MarketsContext(ctx context.Context) ([]Market, error) {
+	var r markets
+	err := c.call(ctx, "GET", "/api/exchange/1/markets", nil, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	raws := r.rawList("markets")
+	ms := make([]Market, len(r.Markets))
+	for i, m := range r.Markets {
+		field := index("markets", i)
+		ms[i] = Market{
+			Pair:            m.MarketId,
+			Status:          MarketStatus(m.TradingStatus),
+			BaseCurrency:    m.BaseCurrency,
+			CounterCurrency: m.CounterCurrency,
+			MinVolume:       p.amount(field+".min_volume", m.MinVolume),
+			MaxVolume:       p.amount(field+".max_volume", m.MaxVolume),
+			VolumeScale:     m.VolumeScale,
+			MinPrice:        p.amount(field+".min_price", m.MinPrice),
+			MaxPrice:        p.amount(field+".max_price", m.MaxPrice),
+			PriceScale:      m.PriceScale,
+			Raw:             rawAt(raws, i),
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return ms, nil
+}
+
+// Returns the market for a currency pair, or ErrUnknownMarket.
+func (c *Client) Market(pair string) (*Market, error) {
+	return c.MarketContext(context.Background(), pair)
+}
+
+// MarketContext is like Market but takes a context.
+func (c *Client) MarketContext(ctx context.Context, pair string) (
+	*Market, error) {
+	ms, err := c.MarketsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ms {
+		if ms[i].Pair == pair {
+			return &ms[i], nil
+		}
+	}
+	return nil, ErrUnknownMarket
+}
+
+// WithOrderValidation makes PostOrder validate orders with
+// Market.ValidateOrder before sending them, rounding their volume and price
+// or returning an error instead of a rejection from the exchange. Markets are
+// fetched on first use and cached for the life of the client.
+func WithOrderValidation() Option {
+	return func(c *Client) {
+		c.validateOrders = true
+	}
+}
+
+// cachedMarket returns the market for a pair, fetching the markets if they
+// haven't been fetched yet. The lock isn't held while fetching, so concurrent
+// first calls may each fetch the markets, and a failed fetch isn't cached.
+func (c *Client) cachedMarket(ctx context.Context, pair string) (
+	*Market, error) {
+	c.marketsMu.Lock()
+	markets := c.markets
+	c.marketsMu.Unlock()
+
+	if markets == nil {
+		ms, err := c.MarketsContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		markets = make(map[string]Market, len(ms))
+		for _, m := range ms {
+			markets[m.Pair] = m
+		}
+		c.marketsMu.Lock()
+		c.markets = markets
+		c.marketsMu.Unlock()
+	}
+
+	m, ok := markets[pair]
+	if !ok {
+		return nil, ErrUnknownMarket
+	}
+	return &m, nil
+}
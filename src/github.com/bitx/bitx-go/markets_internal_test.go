@@ -0,0 +1,99 @@
+package bitx
+
+import (
+	"net/http"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestValidateOrder(t *testing.T) {
+	m := &Market{
+		Pair:        "XBTZAR",
+		Status:      Active,
+		MinVolume:   MustParseAmount("0.0005"),
+		MaxVolume:   100 * Unit,
+		VolumeScale: 4,
+		MinPrice:    Unit,
+		MaxPrice:    1000000 * Unit,
+		PriceScale:  0,
+	}
+	for _, test := range []struct {
+		typ                   OrderType
+		volume, price         string
+		wantVolume, wantPrice string
+		wantField             string
+	}{
+		{BID, "1.23456", "5000.9", "1.2345", "5000", ""},
+		{ASK, "1.23456", "5000.1", "1.2345", "5001", ""},
+		{ASK, "2", "5000", "2", "5000", ""},
+		{BID, "0.00049", "5000", "", "", "volume"},
+		{BID, "101", "5000", "", "", "volume"},
+		{ASK, "1", "1000000.5", "", "", "price"},
+		{BID, "1", "0.5", "", "", "price"},
+	} {
+		volume, price, err := m.ValidateOrder(test.typ,
+			MustParseAmount(test.volume), MustParseAmount(test.price))
+		if test.wantField != "" {
+			if e, ok := err.(*OrderError); !ok || e.Field != test.wantField {
+				t.Errorf("%s %s at %s: expected %s error, got %v",
+					test.typ, test.volume, test.price, test.wantField, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s at %s: %v", test.typ, test.volume, test.price, err)
+			continue
+		}
+		if volume != MustParseAmount(test.wantVolume) ||
+			price != MustParseAmount(test.wantPrice) {
+			t.Errorf("%s %s at %s: expected %s at %s, got %s at %s",
+				test.typ, test.volume, test.price, test.wantVolume,
+				test.wantPrice, volume, price)
+		}
+	}
+
+	m.Status = Disabled
+	if _, _, err := m.ValidateOrder(BID, Unit, Unit); err != ErrMarketNotActive {
+		t.Errorf("Expected %v, got %v", ErrMarketNotActive, err)
+	}
+}
+
+func TestTick(t *testing.T) {
+	for scale, want := range []Amount{Unit, Unit / 10, Unit / 100} {
+		if got := tick(scale); got != want {
+			t.Errorf("Expected tick %s for scale %d, got %s", want, scale, got)
+		}
+	}
+	if got := tick(12); got != 1 {
+		t.Errorf("Expected tick of 1e-8 for scale 12, got %s", got)
+	}
+}
+
+func TestCachedMarketRetriesFailedFetch(t *testing.T) {
+	var n int
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n++
+		if n == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"markets":[]}`))
+	})
+	defer s.Close()
+	c := NewClient("", "", withBase, WithRetry(0, 0))
+
+	_, err := c.cachedMarket(context.Background(), "XBTZAR")
+	if err == nil || err == ErrUnknownMarket {
+		t.Fatalf("Expected error from failed fetch, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := c.cachedMarket(context.Background(), "XBTZAR")
+		if err != ErrUnknownMarket {
+			t.Errorf("Expected ErrUnknownMarket, got %v", err)
+		}
+	}
+	if n != 2 {
+		t.Errorf("Expected markets to be fetched twice, got %d", n)
+	}
+}
@@ -0,0 +1,56 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestMarkets(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+	s.SetMarket(bitx.Market{Pair: "ETHZAR", Status: bitx.Disabled,
+		BaseCurrency: "ETH", CounterCurrency: "ZAR"})
+
+	ms, err := c.Markets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 || ms[0].Pair != "ETHZAR" || ms[1].Pair != "XBTZAR" ||
+		ms[1].MinVolume != amount("0.0005") || ms[1].VolumeScale != 4 {
+		t.Errorf("Expected ETHZAR and XBTZAR markets, got %+v", ms)
+	}
+
+	// The exchange rejects orders outside the market's limits.
+	_, err = c.PostOrder("XBTZAR", bitx.BID, amount("0.12345"),
+		amount("5000"))
+	if e, ok := err.(*bitx.APIError); !ok || e.Code != "ErrTooPrecise" {
+		t.Errorf("Expected precision error, got %v", err)
+	}
+
+	// A validating client rounds them instead.
+	vc := s.Client(bitx.WithOrderValidation())
+	id, err := vc.PostOrder("XBTZAR", bitx.BID, amount("0.12345"),
+		amount("5000.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := vc.GetOrder(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.LimitVolume != amount("0.1234") || o.LimitPrice != amount("5000") {
+		t.Errorf("Expected rounded order, got %+v", o)
+	}
+	_, err = vc.PostOrder("XBTZAR", bitx.BID, amount("0.0001"), amount("5000"))
+	if _, ok := err.(*bitx.OrderError); !ok {
+		t.Errorf("Expected *bitx.OrderError, got %v", err)
+	}
+	_, err = vc.PostOrder("ETHZAR", bitx.BID, amount("1"), amount("5000"))
+	if err != bitx.ErrMarketNotActive {
+		t.Errorf("Expected %v, got %v", bitx.ErrMarketNotActive, err)
+	}
+	_, err = vc.PostOrder("XBTNGN", bitx.BID, amount("1"), amount("5000"))
+	if err != bitx.ErrUnknownMarket {
+		t.Errorf("Expected %v, got %v", bitx.ErrUnknownMarket, err)
+	}
+}
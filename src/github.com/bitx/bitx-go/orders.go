@@ -0,0 +1,194 @@
+package bitx
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ListOrdersOptions selects the orders returned by ListOrdersWithOptions.
+// The zero value selects the most recent orders for all pairs.
+type ListOrdersOptions struct {
+	// Pair restricts the list to a currency pair, e.g. XBTZAR.
+	Pair string
+	// State restricts the list to orders in a state, e.g. Pending.
+	State OrderState
+	// CreatedBefore restricts the list to orders created before the time.
+	CreatedBefore time.Time
+	// Limit is the maximum number of orders to return. The server's default
+	// is used if it is zero.
+	Limit int
+}
+
+func (opts ListOrdersOptions) values() url.Values {
+	params := url.Values{}
+	if opts.Pair != "" {
+		params.Set("pair", opts.Pair)
+	}
+	if opts.State != "" {
+		params.Set("state", string(opts.State))
+	}
+	if !opts.CreatedBefore.IsZero() {
+		params.Set("created_before",
+			strconv.FormatInt(opts.CreatedBefore.UnixNano()/1e6, 10))
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	return params
+}
+
+// Returns a list of orders selected by opts, most recently placed first.
+func (c *Client) ListOrdersWithOptions(opts ListOrdersOptions) ([]Order, error) {
+	return c.ListOrdersWithOptionsContext(context.Background(), opts)
+}
+
+// ListOrdersWithOptionsContext is like ListOrdersWithOptions but takes a
+// context.
+func (c *Client) ListOrdersWithOptionsContext(ctx context.Context,
+	opts ListOrdersOptions) ([]Order, error) {
+	orders, _, err := c.listOrders(ctx, opts.values())
+	return orders, err
+}
+
+// listOrders returns the orders and their creation timestamps in
+// milliseconds.
+func (c *Client) listOrders(ctx context.Context, params url.Values) (
+	[]Order, []int64, error) {
+	var r orders
+	err := c.call(ctx, "GET", "/api/1/listorders", params, &r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := c.parser()
+	raws := r.rawList("orders")
+	orders := make([]Order, len(r.Orders))
+	created := make([]int64, len(r.Orders))
+	for i, bo := range r.Orders {
+		orders[i] = parseOrder(p, index("orders", i)+".", bo)
+		orders[i].Raw = rawAt(raws, i)
+		created[i] = bo.CreationTimestamp
+	}
+	if p.err != nil {
+		return nil, nil, p.err
+	}
+	return orders, created, nil
+}
+
+// defaultOrdersPageSize is the page size of an OrderIterator if
+// ListOrdersOptions doesn't set one.
+const defaultOrdersPageSize = 100
+
+// OrderIterator pages through the orders selected by ListOrdersOptions, most
+// recently placed first. Each page after the first lists the orders created
+// up to and including the millisecond of the oldest order of the previous
+// page, skipping those already returned. Only if more than a page of orders
+// share one millisecond can some of them be skipped.
+//
+//	it := c.Orders(ctx, bitx.ListOrdersOptions{Pair: "XBTZAR"})
+//	for it.Next() {
+//		o := it.Order()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type OrderIterator struct {
+	c      *Client
+	ctx    context.Context
+	params url.Values
+	limit  int
+
+	// boundary is the creation time in milliseconds of the oldest order
+	// returned so far and seen holds the ids of the orders returned with
+	// that creation time.
+	boundary int64
+	seen     map[string]bool
+
+	page  []Order
+	order Order
+	done  bool
+	err   error
+}
+
+// Orders returns an iterator over all orders selected by opts. Limit sets
+// the page size, which is 100 if it is zero.
+func (c *Client) Orders(ctx context.Context,
+	opts ListOrdersOptions) *OrderIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultOrdersPageSize
+	}
+	return &OrderIterator{
+		c:      c,
+		ctx:    ctx,
+		params: opts.values(),
+		limit:  opts.Limit,
+	}
+}
+
+// Next advances to the next order, fetching another page if necessary. It
+// returns false when there are no more orders or an error occurs.
+func (it *OrderIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	it.order, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *OrderIterator) fetch() {
+	orders, created, err := it.c.listOrders(it.ctx, it.params)
+	if err != nil {
+		it.err = err
+		return
+	}
+	var oldest int64
+	for _, ms := range created {
+		if oldest == 0 || ms < oldest {
+			oldest = ms
+		}
+	}
+	seen := it.seen
+	if seen == nil || oldest != it.boundary {
+		seen = make(map[string]bool)
+	}
+	it.page = nil
+	for i, o := range orders {
+		if it.seen[o.Id] {
+			continue
+		}
+		if created[i] == oldest {
+			seen[o.Id] = true
+		}
+		it.page = append(it.page, o)
+	}
+	it.boundary, it.seen = oldest, seen
+	if len(orders) < it.limit {
+		it.done = true
+		return
+	}
+	if len(it.page) == 0 {
+		// The whole page was created in one millisecond and has already
+		// been returned, so move on to older orders.
+		it.params.Set("created_before", strconv.FormatInt(oldest, 10))
+		it.boundary, it.seen = 0, nil
+		return
+	}
+	it.params.Set("created_before", strconv.FormatInt(oldest+1, 10))
+}
+
+// Order returns the current order.
+func (it *OrderIterator) Order() Order {
+	return it.order
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *OrderIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,111 @@
+package bitx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestListOrdersWithOptions(t *testing.T) {
+	var query string
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"orders":[{"order_id":"BXID1","pair":"XBTZAR",
+			"state":"AWAITING","type":"BID","creation_timestamp":1000,
+			"expiration_timestamp":5000,"limit_price":"1","limit_volume":"1",
+			"base":"0","counter":"0","fee_base":"0","fee_counter":"0"}]}`))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase)
+	orders, err := c.ListOrdersWithOptions(ListOrdersOptions{
+		Pair:          "XBTZAR",
+		State:         Pending,
+		CreatedBefore: time.Unix(2, 0),
+		Limit:         10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "created_before=2000&limit=10&pair=XBTZAR&state=PENDING"
+	if query != want {
+		t.Errorf("Expected query %s, got %s", want, query)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("Expected one order, got %+v", orders)
+	}
+	o := orders[0]
+	if o.Pair != "XBTZAR" || o.State != Awaiting {
+		t.Errorf("Expected awaiting XBTZAR order, got %+v", o)
+	}
+	if !o.CompletedAt.IsZero() || !o.ExpiresAt.Equal(time.Unix(5, 0)) {
+		t.Errorf("Expected expiry and no completion time, got %+v", o)
+	}
+}
+
+func TestUnknownOrderStatePreserved(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"order_id":"BXID1","state":"CANCELLING",
+			"limit_price":"1","limit_volume":"1","base":"0","counter":"0",
+			"fee_base":"0","fee_counter":"0"}`))
+	})
+	defer s.Close()
+
+	o, err := NewClient("", "", withBase).GetOrder("BXID1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.State != OrderState("CANCELLING") {
+		t.Errorf("Expected state CANCELLING, got %s", o.State)
+	}
+}
+
+func TestOrderIteratorSameMillisecond(t *testing.T) {
+	// Orders B, C and D are created in the same millisecond and straddle
+	// the first page boundary.
+	created := []struct {
+		id string
+		ms int64
+	}{{"A", 3000}, {"B", 2000}, {"C", 2000}, {"D", 2000}, {"E", 1000}}
+
+	var requests int
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		before, _ := strconv.ParseInt(r.FormValue("created_before"), 10, 64)
+		var l []string
+		for _, o := range created {
+			if len(l) == limit || before != 0 && o.ms >= before {
+				continue
+			}
+			l = append(l, fmt.Sprintf(`{"order_id":%q,"creation_timestamp":%d,`+
+				`"limit_price":"1","limit_volume":"1","base":"0","counter":"0",`+
+				`"fee_base":"0","fee_counter":"0"}`, o.id, o.ms))
+		}
+		fmt.Fprintf(w, `{"orders":[%s]}`, strings.Join(l, ","))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase)
+	it := c.Orders(context.Background(), ListOrdersOptions{Limit: 3})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Order().Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(ids, ""); got != "ABCDE" {
+		t.Errorf("Expected orders ABCDE, got %s", got)
+	}
+	// ABC, then BCD, then BCD again with all seen, then E, which is a short
+	// page and the last.
+	if requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", requests)
+	}
+}
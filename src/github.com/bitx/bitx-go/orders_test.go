@@ -0,0 +1,119 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestListOrders(t *testing.T) {
+	s, c := newFake(t, "ZAR", "100000", "XBT", "10")
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("1000"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := c.PostOrder("XBTNGN", bitx.ASK, amount("1"),
+		amount("90000")); err != nil {
+		t.Fatal(err)
+	}
+	// Fill the two oldest bids.
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("2"), amount("1000"))
+
+	pending, err := c.ListOrdersWithOptions(bitx.ListOrdersOptions{
+		Pair: "XBTZAR", State: bitx.Pending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 || pending[0].Id != ids[4] || pending[2].Id != ids[2] {
+		t.Errorf("Expected three newest XBTZAR bids, got %+v", pending)
+	}
+	for _, o := range pending {
+		if o.Pair != "XBTZAR" || !o.CompletedAt.IsZero() {
+			t.Errorf("Expected pending XBTZAR order, got %+v", o)
+		}
+	}
+
+	complete, err := c.ListOrdersWithOptions(bitx.ListOrdersOptions{
+		State: bitx.Complete, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(complete) != 1 || complete[0].Id != ids[1] ||
+		complete[0].CompletedAt.IsZero() {
+		t.Errorf("Expected most recent complete order %s, got %+v",
+			ids[1], complete)
+	}
+
+	it := c.Orders(context.Background(), bitx.ListOrdersOptions{
+		Pair: "XBTZAR", Limit: 2})
+	var got []string
+	for it.Next() {
+		got = append(got, it.Order().Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(ids) {
+		t.Fatalf("Expected %d orders, got %v", len(ids), got)
+	}
+	for i, id := range got {
+		if want := ids[len(ids)-1-i]; id != want {
+			t.Errorf("Expected order %s at %d, got %s", want, i, id)
+		}
+	}
+}
+
+func TestMarketOrders(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("6000"))
+
+	id, err := c.PostMarketOrder("XBTZAR", bitx.BUY, amount("8000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := c.GetOrder(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Type != bitx.BUY || o.State != bitx.Complete ||
+		o.Base != amount("1.5") || o.Counter != amount("8000") {
+		t.Errorf("Expected complete buy of 1.5 XBT, got %+v", o)
+	}
+	expectBalance(t, s, "XBT", "1.5", "0")
+	expectBalance(t, s, "ZAR", "2000", "0")
+
+	// Only 0.5 XBT of bids is available, so the rest is cancelled.
+	s.PlaceOrder("XBTZAR", bitx.BID, amount("0.5"), amount("4000"))
+	id, err = c.PostMarketOrder("XBTZAR", bitx.SELL, amount("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err = c.GetOrder(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Type != bitx.SELL || o.State != bitx.Complete ||
+		o.Base != amount("0.5") || o.Counter != amount("2000") {
+		t.Errorf("Expected complete sell of 0.5 XBT, got %+v", o)
+	}
+	expectBalance(t, s, "XBT", "1", "0")
+	expectBalance(t, s, "ZAR", "4000", "0")
+
+	if _, err := c.PostMarketOrder("XBTZAR", bitx.SELL,
+		amount("2")); err == nil {
+		t.Errorf("Expected error for insufficient balance")
+	}
+	if _, err := c.PostMarketOrder("XBTZAR", bitx.BID,
+		amount("1")); err == nil {
+		t.Errorf("Expected error for limit order type")
+	}
+}
@@ -0,0 +1,124 @@
+package bitx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError indicates that a field of an API response could not be parsed.
+type ParseError struct {
+	// Field is the path of the field in the response, e.g. "bids[3].price".
+	Field string
+	// Value is the raw value of the field.
+	Value string
+	// Err is the reason parsing failed.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("bitx: cannot parse %s %q: %v", e.Field, e.Value, e.Err)
+}
+
+// WithLenientParsing makes the client treat fields of API responses which
+// cannot be parsed as zero instead of returning a *ParseError. It is meant
+// for exploratory tools and should not be used when trading.
+func WithLenientParsing() Option {
+	return func(c *Client) {
+		c.lenient = true
+	}
+}
+
+// parser converts the string fields of API responses. It records the first
+// error so that a response can be converted without checking every field.
+type parser struct {
+	lenient bool
+	err     error
+}
+
+func (c *Client) parser() *parser {
+	return &parser{lenient: c.lenient}
+}
+
+// amount parses a decimal field. It returns zero if the field is invalid.
+func (p *parser) amount(field, s string) Amount {
+	a, err := ParseAmount(s)
+	p.check(field, s, err)
+	return a
+}
+
+// number parses a JSON number field, which may use exponent notation. It
+// returns zero if the field is invalid.
+func (p *parser) number(field string, n json.Number) Amount {
+	s := string(n)
+	if strings.ContainsAny(s, "eE") {
+		if r, ok := new(big.Rat).SetString(s); ok {
+			// Extra digits are kept so that ParseAmount rejects values
+			// which are too precise.
+			s = r.FloatString(2 * decimals)
+		}
+	}
+	a, err := ParseAmount(s)
+	p.check(field, string(n), err)
+	return a
+}
+
+// check records err as the parser's error if it is the first.
+func (p *parser) check(field, value string, err error) {
+	if err != nil && !p.lenient && p.err == nil {
+		p.err = &ParseError{Field: field, Value: value, Err: err}
+	}
+}
+
+// WithRawJSON makes the client keep the JSON each value was decoded from in
+// its Raw field, e.g. to inspect fields which the package doesn't decode yet.
+func WithRawJSON() Option {
+	return func(c *Client) {
+		c.rawJSON = true
+	}
+}
+
+// rawList returns the elements of a list field of the response, or nil if
+// the client doesn't keep raw JSON.
+func (r *apiResponse) rawList(field string) []json.RawMessage {
+	if r.raw == nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	var l []json.RawMessage
+	if json.Unmarshal(r.raw, &fields) != nil ||
+		json.Unmarshal(fields[field], &l) != nil {
+		return nil
+	}
+	return l
+}
+
+// rawAt returns the ith element of l, or nil if there isn't one.
+func rawAt(l []json.RawMessage, i int) json.RawMessage {
+	if i < len(l) {
+		return l[i]
+	}
+	return nil
+}
+
+// millis converts a timestamp in milliseconds since the Unix epoch.
+func millis(ms int64) time.Time {
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond))
+}
+
+// optionalMillis is like millis but returns the zero time if ms is zero,
+// for timestamps which may not be set.
+func optionalMillis(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return millis(ms)
+}
+
+// index returns the path of an element of a list field, e.g. "bids[3]".
+func index(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,128 @@
+package bitx
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const malformedOrderBook = `{"bids":[{"price":"100","volume":"1"},` +
+	`{"price":"99","volume":"1,5"}],"asks":[]}`
+
+func TestStrictParsing(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(malformedOrderBook))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase)
+	_, _, err := c.OrderBook("XBTZAR")
+	e, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("Expected *ParseError, got %v", err)
+	}
+	if e.Field != "bids[1].volume" || e.Value != "1,5" {
+		t.Errorf("Expected bids[1].volume \"1,5\", got %s %q", e.Field, e.Value)
+	}
+}
+
+func TestStrictParsingMissingField(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"order_id":"BXID1","limit_price":"100",` +
+			`"limit_volume":"1","base":"0","counter":"0","fee_base":"0"}`))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase)
+	_, err := c.GetOrder("BXID1")
+	if e, ok := err.(*ParseError); !ok || e.Field != "fee_counter" {
+		t.Errorf("Expected error for fee_counter, got %v", err)
+	}
+}
+
+func TestLenientParsing(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(malformedOrderBook))
+	})
+	defer s.Close()
+
+	c := NewClient("", "", withBase, WithLenientParsing())
+	bids, _, err := c.OrderBook("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bids) != 2 || bids[0].Volume != Unit || bids[1].Volume != 0 {
+		t.Errorf("Expected invalid volume to be zero, got %v", bids)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, test := range []struct {
+		in   json.Number
+		want Amount
+		ok   bool
+	}{
+		{"0.5", Unit / 2, true},
+		{"-12", -12 * Unit, true},
+		{"1e-05", 1000, true},
+		{"2.5E+3", 2500 * Unit, true},
+		{"1e-09", 0, false},
+		{"abc", 0, false},
+	} {
+		p := &parser{}
+		got := p.number("balance", test.in)
+		if test.ok && (p.err != nil || got != test.want) {
+			t.Errorf("Expected %s to parse as %s, got %s (%v)",
+				test.in, test.want, got, p.err)
+		}
+		if !test.ok {
+			if e, ok := p.err.(*ParseError); !ok || e.Value != string(test.in) {
+				t.Errorf("Expected ParseError for %s, got %v", test.in, p.err)
+			}
+		}
+	}
+}
+
+const tradesJSON = `{"trades":[` +
+	`{"timestamp":1500,"price":"100","volume":"1","extra":"a"},` +
+	`{"timestamp":2001,"price":"101","volume":"2"}]}`
+
+func TestMillisecondTimestamps(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(tradesJSON))
+	})
+	defer s.Close()
+
+	tr, err := NewClient("", "", withBase).Trades("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Unix(1, 5e8); len(tr) != 2 || !tr[0].Timestamp.Equal(want) {
+		t.Fatalf("Expected first trade at %v, got %v", want, tr)
+	}
+	if tr[0].Raw != nil {
+		t.Errorf("Expected no raw JSON by default, got %s", tr[0].Raw)
+	}
+}
+
+func TestRawJSON(t *testing.T) {
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(tradesJSON))
+	})
+	defer s.Close()
+
+	tr, err := NewClient("", "", withBase, WithRawJSON()).Trades("XBTZAR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var extra struct {
+		Extra string `json:"extra"`
+	}
+	if err := json.Unmarshal(tr[0].Raw, &extra); err != nil || extra.Extra != "a" {
+		t.Errorf("Expected raw trade with extra field, got %s", tr[0].Raw)
+	}
+	if want := `{"timestamp":2001,"price":"101","volume":"2"}`; string(tr[1].Raw) != want {
+		t.Errorf("Expected raw trade %s, got %s", want, tr[1].Raw)
+	}
+}
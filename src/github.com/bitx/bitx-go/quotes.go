@@ -0,0 +1,141 @@
+package bitx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type quote struct {
+	apiResponse
+	Id            string `json:"id"`
+	Type          string `json:"type"`
+	Pair          string `json:"pair"`
+	BaseAmount    string `json:"base_amount"`
+	CounterAmount string `json:"counter_amount"`
+	CreatedAt     int64  `json:"created_at"`
+	ExpiresAt     int64  `json:"expires_at"`
+	Discarded     bool   `json:"discarded"`
+	Exercised     bool   `json:"exercised"`
+}
+
+// Quote is a fixed price offer to buy or sell an amount of the base currency
+// of a pair, valid until it expires.
+type Quote struct {
+	Id   string
+	Type OrderType
+	Pair string
+	// BaseAmount is bought or sold for CounterAmount.
+	BaseAmount, CounterAmount Amount
+	CreatedAt, ExpiresAt      time.Time
+	Discarded, Exercised      bool
+	Raw                       json.RawMessage // See WithRawJSON.
+}
+
+// Expired reports whether the quote has expired at time t.
+func (q *Quote) Expired(t time.Time) bool {
+	return !t.Before(q.ExpiresAt)
+}
+
+func (c *Client) quote(ctx context.Context, method, path string,
+	params url.Values) (*Quote, error) {
+	var r quote
+	err := c.call(ctx, method, path, params, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	q := &Quote{
+		Id:            r.Id,
+		Type:          OrderType(r.Type),
+		Pair:          r.Pair,
+		BaseAmount:    p.amount("base_amount", r.BaseAmount),
+		CounterAmount: p.amount("counter_amount", r.CounterAmount),
+		CreatedAt:     millis(r.CreatedAt),
+		ExpiresAt:     millis(r.ExpiresAt),
+		Discarded:     r.Discarded,
+		Exercised:     r.Exercised,
+		Raw:           r.raw,
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return q, nil
+}
+
+// Creates a quote to BUY or SELL baseAmount of the base currency of a pair.
+// The quote must be exercised before it expires to trade.
+func (c *Client) CreateQuote(pair string, quote_type OrderType,
+	baseAmount Amount) (*Quote, error) {
+	return c.CreateQuoteContext(context.Background(), pair, quote_type,
+		baseAmount)
+}
+
+// CreateQuoteContext is like CreateQuote but takes a context.
+func (c *Client) CreateQuoteContext(ctx context.Context, pair string,
+	quote_type OrderType, baseAmount Amount) (*Quote, error) {
+	if quote_type != BUY && quote_type != SELL {
+		return nil, errors.New("Quote type must be BUY or SELL")
+	}
+	form := make(url.Values)
+	form.Add("type", string(quote_type))
+	form.Add("pair", pair)
+	form.Add("base_amount", baseAmount.String())
+	return c.quote(ctx, "POST", "/api/1/quotes", form)
+}
+
+func quotePath(id string) (string, error) {
+	if !isValidPathID(id) {
+		return "", errors.New("invalid quote id")
+	}
+	return "/api/1/quotes/" + id, nil
+}
+
+// Get a quote by its id.
+func (c *Client) GetQuote(id string) (*Quote, error) {
+	return c.GetQuoteContext(context.Background(), id)
+}
+
+// GetQuoteContext is like GetQuote but takes a context.
+func (c *Client) GetQuoteContext(ctx context.Context, id string) (
+	*Quote, error) {
+	path, err := quotePath(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.quote(ctx, "GET", path, nil)
+}
+
+// Exercises a quote, trading at its price, and returns it.
+func (c *Client) ExerciseQuote(id string) (*Quote, error) {
+	return c.ExerciseQuoteContext(context.Background(), id)
+}
+
+// ExerciseQuoteContext is like ExerciseQuote but takes a context.
+func (c *Client) ExerciseQuoteContext(ctx context.Context, id string) (
+	*Quote, error) {
+	path, err := quotePath(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.quote(ctx, "PUT", path, nil)
+}
+
+// Discards a quote so that it can no longer be exercised, and returns it.
+func (c *Client) DiscardQuote(id string) (*Quote, error) {
+	return c.DiscardQuoteContext(context.Background(), id)
+}
+
+// DiscardQuoteContext is like DiscardQuote but takes a context.
+func (c *Client) DiscardQuoteContext(ctx context.Context, id string) (
+	*Quote, error) {
+	path, err := quotePath(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.quote(ctx, "DELETE", path, nil)
+}
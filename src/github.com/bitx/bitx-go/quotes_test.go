@@ -0,0 +1,72 @@
+package bitx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestQuotes(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("6000"))
+
+	if _, err := c.CreateQuote("XBTZAR", bitx.BUY, amount("3")); err == nil {
+		t.Errorf("Expected error for insufficient liquidity")
+	}
+
+	q, err := c.CreateQuote("XBTZAR", bitx.BUY, amount("1.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Type != bitx.BUY || q.Pair != "XBTZAR" ||
+		q.BaseAmount != amount("1.5") || q.CounterAmount != amount("8000") {
+		t.Errorf("Expected quote to buy 1.5 XBT for 8000 ZAR, got %+v", q)
+	}
+	if q.Expired(time.Now()) || !q.Expired(q.ExpiresAt) {
+		t.Errorf("Expected quote to expire at %v", q.ExpiresAt)
+	}
+
+	q, err = c.ExerciseQuote(q.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Exercised {
+		t.Errorf("Expected exercised quote, got %+v", q)
+	}
+	expectBalance(t, s, "ZAR", "2000", "0")
+	expectBalance(t, s, "XBT", "1.5", "0")
+	if _, err := c.ExerciseQuote(q.Id); err == nil {
+		t.Errorf("Expected error exercising twice")
+	}
+
+	q, err = c.CreateQuote("XBTZAR", bitx.BUY, amount("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DiscardQuote(q.Id); err != nil {
+		t.Fatal(err)
+	}
+	q, err = c.GetQuote(q.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Discarded || q.Exercised {
+		t.Errorf("Expected discarded quote, got %+v", q)
+	}
+	if _, err := c.ExerciseQuote(q.Id); err == nil {
+		t.Errorf("Expected error exercising discarded quote")
+	}
+
+	s.SetQuoteTTL(0)
+	q, err = c.CreateQuote("XBTZAR", bitx.BUY, amount("0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.ExerciseQuote(q.Id)
+	if e, ok := err.(*bitx.APIError); !ok || e.Code != "ErrQuoteExpired" {
+		t.Errorf("Expected expired quote error, got %v", err)
+	}
+}
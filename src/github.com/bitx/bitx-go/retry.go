@@ -0,0 +1,119 @@
+package bitx
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// maxBackoff is the longest time to wait between retries.
+const maxBackoff = 30 * time.Second
+
+// defaultMaxRetries is how many times a client retries GET requests unless
+// WithRetry says otherwise.
+const defaultMaxRetries = 3
+
+// defaultRetryBase is the first wait between retries unless WithRetry gives
+// a base.
+const defaultRetryBase = time.Second
+
+// WithLimiter makes the client wait for l before sending each request,
+// including retries. Pass the same limiter to every client using an API key
+// to share its request budget.
+func WithLimiter(l *Limiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// WithRateLimit limits the client to rate requests per second on average,
+// with bursts of up to burst requests.
+func WithRateLimit(rate float64, burst int) Option {
+	return WithLimiter(NewLimiter(rate, burst))
+}
+
+// WithRetry retries GET requests up to max times if they fail with a
+// retryable API error or a network error. The wait between attempts starts
+// at base, or one second if base is zero, and doubles with each attempt,
+// with random jitter. Clients retry GET requests 3 times with a one second
+// base by default; pass a max of zero to disable retries.
+func WithRetry(max int, base time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max
+		c.retryBase = base
+	}
+}
+
+// WithPostRetries also retries POST, PUT and DELETE requests like PostOrder,
+// Send and CancelWithdrawal under the conditions set by WithRetry, and after
+// server errors. This is unsafe unless duplicate requests are acceptable: a
+// request which failed with a server or network error may still have taken
+// effect.
+func WithPostRetries() Option {
+	return func(c *Client) {
+		c.retryPost = true
+	}
+}
+
+// call sends a request, waiting for the rate limiter and retrying as
+// configured.
+func (c *Client) call(ctx context.Context, method, path string,
+	params url.Values, result interface{}) error {
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		req := &Request{Method: method, Path: path, Params: params,
+			Header: make(http.Header), Attempt: attempt, Result: result}
+		transient, err := c.invoke(ctx, req)
+		if err == nil || attempt >= c.maxRetries ||
+			!c.shouldRetry(method, err, transient) {
+			return err
+		}
+
+		t := time.NewTimer(c.backoff(attempt, err))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) shouldRetry(method string, err error, transient bool) bool {
+	if method != "GET" && !c.retryPost {
+		return false
+	}
+	if transient {
+		return true
+	}
+	e, ok := err.(*APIError)
+	if !ok {
+		return false
+	}
+	return e.Retryable || (c.retryPost && e.StatusCode >= 500)
+}
+
+// backoff returns a random wait of between half and all of the exponential
+// backoff for the attempt, or the wait requested by the API.
+func (c *Client) backoff(attempt int, err error) time.Duration {
+	if e, ok := err.(*APIError); ok && e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	base := c.retryBase
+	if base <= 0 {
+		base = defaultRetryBase
+	}
+	d := base << uint(attempt)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
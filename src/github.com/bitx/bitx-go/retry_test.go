@@ -0,0 +1,165 @@
+package bitx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestLimiterBurst(t *testing.T) {
+	l := NewLimiter(10, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Errorf("Expected burst without waiting, took %v", d)
+	}
+	l.Wait(context.Background())
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("Expected to wait after burst, took %v", d)
+	}
+}
+
+func TestLimiterContext(t *testing.T) {
+	l := NewLimiter(0.001, 1)
+	l.Wait(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+// flakyServer fails the first n requests with the given status.
+func flakyServer(t *testing.T, n, status int) (*int, Option, func()) {
+	var requests int
+	s, withBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= n {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{"success":true,"bid":"1","ask":"1","last_trade":"1",` +
+			`"rolling_24_hour_volume":"1"}`))
+	})
+	return &requests, withBase, s.Close
+}
+
+func TestRetryGet(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 2, http.StatusTooManyRequests)
+	defer stop()
+
+	c := NewClient("", "", withBase, WithRetry(2, time.Millisecond))
+	if _, err := c.Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", *requests)
+	}
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 5, http.StatusServiceUnavailable)
+	defer stop()
+
+	c := NewClient("", "", withBase, WithRetry(2, time.Millisecond))
+	if _, err := c.Ticker("XBTZAR"); !IsRetryable(err) {
+		t.Errorf("Expected retryable error, got %v", err)
+	}
+	if *requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", *requests)
+	}
+}
+
+func TestRetryByDefault(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 1, http.StatusTooManyRequests)
+	defer stop()
+
+	if _, err := NewClient("", "", withBase).Ticker("XBTZAR"); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", *requests)
+	}
+
+	requests, withBase, stop = flakyServer(t, 1, http.StatusTooManyRequests)
+	defer stop()
+	c := NewClient("", "", withBase, WithRetry(0, 0))
+	if _, err := c.Ticker("XBTZAR"); !IsRetryable(err) {
+		t.Errorf("Expected retryable error, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("Expected 1 request with retries disabled, got %d", *requests)
+	}
+}
+
+func TestNoRetryPost(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 1, http.StatusTooManyRequests)
+	defer stop()
+
+	c := NewClient("", "", withBase, WithRetry(2, time.Millisecond))
+	if err := c.StopOrder("BXID1"); err == nil {
+		t.Errorf("Expected error")
+	}
+	if *requests != 1 {
+		t.Errorf("Expected 1 request, got %d", *requests)
+	}
+}
+
+func TestRetryPostOptIn(t *testing.T) {
+	requests, withBase, stop := flakyServer(t, 1, http.StatusInternalServerError)
+	defer stop()
+
+	c := NewClient("", "", withBase, WithRetry(2, time.Millisecond),
+		WithPostRetries())
+	if err := c.StopOrder("BXID1"); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", *requests)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewClient("", "", WithRetry(10, time.Second))
+	for attempt := 0; attempt < 10; attempt++ {
+		d := c.backoff(attempt, nil)
+		max := time.Second << uint(attempt)
+		if max > maxBackoff {
+			max = maxBackoff
+		}
+		if d < max/2 || d > max {
+			t.Errorf("Attempt %d: expected backoff in [%v, %v], got %v",
+				attempt, max/2, max, d)
+		}
+	}
+	if d := c.backoff(0, &APIError{RetryAfter: 7 * time.Second}); d != 7*time.Second {
+		t.Errorf("Expected Retry-After to be honoured, got %v", d)
+	}
+}
+
+func TestBackoffZeroBase(t *testing.T) {
+	c := NewClient("", "", WithRetry(1, 0))
+	if d := c.backoff(0, nil); d < defaultRetryBase/2 || d > defaultRetryBase {
+		t.Errorf("Expected backoff in [%v, %v], got %v", defaultRetryBase/2,
+			defaultRetryBase, d)
+	}
+}
+
+func TestLimiterInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected NewLimiter(%v, 1) to panic", rate)
+				}
+			}()
+			NewLimiter(rate, 1)
+		}()
+	}
+}
@@ -0,0 +1,76 @@
+package bitx
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// MarketSnapshot is the state of a market fetched by Snapshot.
+type MarketSnapshot struct {
+	Pair       string
+	Ticker     Ticker
+	Bids, Asks []OrderBookEntry
+	Trades     []Trade
+}
+
+// Fetches the ticker, order book and recent trades of each currency pair
+// concurrently, so that the snapshots are taken at about the same time.
+// Requests wait for the client's rate limiter as usual. If any request
+// fails, the rest are cancelled and the first error is returned. The
+// snapshots are in the same order as pairs.
+func (c *Client) Snapshot(pairs ...string) ([]MarketSnapshot, error) {
+	return c.SnapshotContext(context.Background(), pairs...)
+}
+
+// SnapshotContext is like Snapshot but takes a context.
+func (c *Client) SnapshotContext(ctx context.Context, pairs ...string) (
+	[]MarketSnapshot, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, maxConcurrentRequests)
+	)
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := f(); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+
+	snaps := make([]MarketSnapshot, len(pairs))
+	for i, pair := range pairs {
+		s, pair := &snaps[i], pair
+		s.Pair = pair
+		run(func() (err error) {
+			s.Ticker, err = c.TickerContext(ctx, pair)
+			return err
+		})
+		run(func() (err error) {
+			s.Bids, s.Asks, err = c.OrderBookContext(ctx, pair)
+			return err
+		})
+		run(func() (err error) {
+			s.Trades, err = c.TradesContext(ctx, pair)
+			return err
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return snaps, nil
+}
@@ -0,0 +1,64 @@
+package bitx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestTickersAndSnapshot(t *testing.T) {
+	s, c := newFake(t)
+	s.PlaceOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5100"))
+	s.PlaceOrder("ETHXBT", bitx.ASK, amount("2"), amount("0.05"))
+	s.PlaceOrder("ETHXBT", bitx.BID, amount("1"), amount("0.05"))
+
+	tickers, err := c.Tickers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickers) != 2 || tickers[0].Pair != "ETHXBT" ||
+		tickers[0].Last != amount("0.05") || tickers[1].Pair != "XBTZAR" ||
+		tickers[1].Bid != amount("5000") || tickers[1].Ask != amount("5100") {
+		t.Errorf("Expected ETHXBT and XBTZAR tickers, got %+v", tickers)
+	}
+	if tk, err := c.Ticker("XBTZAR"); err != nil || tk.Pair != "XBTZAR" {
+		t.Errorf("Expected XBTZAR ticker, got %+v (%v)", tk, err)
+	}
+
+	snaps, err := c.Snapshot("XBTZAR", "ETHXBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("Expected two snapshots, got %+v", snaps)
+	}
+	xbt, eth := snaps[0], snaps[1]
+	if xbt.Pair != "XBTZAR" || xbt.Ticker.Bid != amount("5000") ||
+		len(xbt.Bids) != 1 || len(xbt.Asks) != 1 || len(xbt.Trades) != 0 {
+		t.Errorf("Unexpected XBTZAR snapshot %+v", xbt)
+	}
+	if eth.Pair != "ETHXBT" || len(eth.Bids) != 0 || len(eth.Asks) != 1 ||
+		len(eth.Trades) != 1 || eth.Asks[0].Volume != amount("1") {
+		t.Errorf("Unexpected ETHXBT snapshot %+v", eth)
+	}
+
+	if _, err := c.Snapshot("XBTZAR", "bad"); err == nil {
+		t.Errorf("Expected error for invalid pair")
+	}
+}
+
+func TestSnapshotRateLimit(t *testing.T) {
+	s, _ := newFake(t)
+	c := s.Client(bitx.WithRateLimit(20, 1))
+
+	start := time.Now()
+	if _, err := c.Snapshot("XBTZAR", "ETHXBT"); err != nil {
+		t.Fatal(err)
+	}
+	// Six requests at 20 per second take at least 250ms after the first.
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("Expected requests to wait for the limiter, took %v", d)
+	}
+}
@@ -0,0 +1,185 @@
+package bitx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// maxTransactionRows is the largest row range the API returns at once.
+const maxTransactionRows = 1000
+
+type transaction struct {
+	RowIndex       int64       `json:"row_index"`
+	Timestamp      int64       `json:"timestamp"`
+	Balance        json.Number `json:"balance"`
+	Available      json.Number `json:"available"`
+	BalanceDelta   json.Number `json:"balance_delta"`
+	AvailableDelta json.Number `json:"available_delta"`
+	Currency       string      `json:"currency"`
+	Description    string      `json:"description"`
+}
+
+type transactions struct {
+	apiResponse
+	Id           string        `json:"id"`
+	Transactions []transaction `json:"transactions"`
+}
+
+// Transaction is a row of an account's ledger.
+type Transaction struct {
+	// RowIndex is the position of the transaction in the ledger, starting
+	// from 1.
+	RowIndex    int64
+	Timestamp   time.Time
+	Description string
+	Currency    string
+	// BalanceDelta and AvailableDelta are the changes to the account's
+	// balance and available (unreserved) balance.
+	BalanceDelta, AvailableDelta Amount
+	// Balance and Available are the account's balances after the
+	// transaction.
+	Balance, Available Amount
+	Raw                json.RawMessage // See WithRawJSON.
+}
+
+// Returns the transactions of an account with row indexes in
+// [minRow, maxRow). The range may span at most 1000 rows.
+func (c *Client) ListTransactions(accountId string, minRow, maxRow int64) (
+	[]Transaction, error) {
+	return c.ListTransactionsContext(context.Background(), accountId,
+		minRow, maxRow)
+}
+
+// ListTransactionsContext is like ListTransactions but takes a context.
+func (c *Client) ListTransactionsContext(ctx context.Context,
+	accountId string, minRow, maxRow int64) ([]Transaction, error) {
+	if !isValidPathID(accountId) {
+		return nil, errors.New("invalid account id")
+	}
+	params := url.Values{
+		"min_row": {strconv.FormatInt(minRow, 10)},
+		"max_row": {strconv.FormatInt(maxRow, 10)},
+	}
+
+	var r transactions
+	err := c.call(ctx, "GET", "/api/1/accounts/"+accountId+"/transactions",
+		params, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	raws := r.rawList("transactions")
+	txns := make([]Transaction, len(r.Transactions))
+	for i, t := range r.Transactions {
+		field := index("transactions", i)
+		txns[i] = Transaction{
+			RowIndex:    t.RowIndex,
+			Timestamp:   millis(t.Timestamp),
+			Description: t.Description,
+			Currency:    t.Currency,
+			BalanceDelta: p.number(field+".balance_delta",
+				t.BalanceDelta),
+			AvailableDelta: p.number(field+".available_delta",
+				t.AvailableDelta),
+			Balance:   p.number(field+".balance", t.Balance),
+			Available: p.number(field+".available", t.Available),
+			Raw:       rawAt(raws, i),
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return txns, nil
+}
+
+// TransactionIterator pages through the transactions of an account in a
+// range of rows, fetching up to 1000 rows at a time.
+//
+//	it := c.Transactions(ctx, accountId, 1, 0)
+//	for it.Next() {
+//		t := it.Transaction()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type TransactionIterator struct {
+	c         *Client
+	ctx       context.Context
+	accountId string
+	// next is the first row of the next page.
+	next, maxRow int64
+
+	page []Transaction
+	txn  Transaction
+	done bool
+	err  error
+}
+
+// Transactions returns an iterator over the transactions of an account with
+// row indexes in [minRow, maxRow). If maxRow is zero, the iterator continues
+// to the latest transaction.
+func (c *Client) Transactions(ctx context.Context, accountId string,
+	minRow, maxRow int64) *TransactionIterator {
+	return &TransactionIterator{
+		c:         c,
+		ctx:       ctx,
+		accountId: accountId,
+		next:      minRow,
+		maxRow:    maxRow,
+	}
+}
+
+// Next advances to the next transaction, fetching another page if
+// necessary. It returns false when there are no more transactions or an
+// error occurs.
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	it.txn, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *TransactionIterator) fetch() {
+	end := it.next + maxTransactionRows
+	if it.maxRow > 0 && end > it.maxRow {
+		end = it.maxRow
+	}
+	if end <= it.next {
+		it.done = true
+		return
+	}
+
+	txns, err := it.c.ListTransactionsContext(it.ctx, it.accountId,
+		it.next, end)
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.page = txns
+	if int64(len(txns)) < end-it.next {
+		// The end of the ledger has been reached.
+		it.done = true
+	}
+	it.next = end
+}
+
+// Transaction returns the current transaction.
+func (it *TransactionIterator) Transaction() Transaction {
+	return it.txn
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
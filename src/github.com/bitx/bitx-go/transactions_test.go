@@ -0,0 +1,92 @@
+package bitx_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestTransactions(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+
+	if _, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"),
+		amount("5000")); err != nil {
+		t.Fatal(err)
+	}
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5000"))
+	if err := c.Send("1000", "ZAR", "addr", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	txns, err := c.ListTransactions(s.AccountID("ZAR"), 1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		balanceDelta, availableDelta, balance, available string
+	}{
+		{"10000", "10000", "10000", "10000"}, // Adjustment
+		{"0", "-5000", "10000", "5000"},      // Reservation
+		{"-5000", "0", "5000", "5000"},       // Fill
+		{"-1000", "-1000", "4000", "4000"},   // Send
+	}
+	if len(txns) != len(want) {
+		t.Fatalf("Expected %d transactions, got %+v", len(want), txns)
+	}
+	for i, w := range want {
+		txn := txns[i]
+		if txn.RowIndex != int64(i+1) || txn.Currency != "ZAR" ||
+			txn.BalanceDelta != amount(w.balanceDelta) ||
+			txn.AvailableDelta != amount(w.availableDelta) ||
+			txn.Balance != amount(w.balance) ||
+			txn.Available != amount(w.available) {
+			t.Errorf("Expected row %d %+v, got %+v", i+1, w, txn)
+		}
+	}
+
+	if _, err := c.ListTransactions("999", 1, 100); err == nil {
+		t.Errorf("Expected error for unknown account")
+	}
+	if _, err := c.ListTransactions(s.AccountID("ZAR"), 1, 2000); err == nil {
+		t.Errorf("Expected error for too many rows")
+	}
+}
+
+func TestTransactionIterator(t *testing.T) {
+	s, c := newFake(t)
+	const n = 2500
+	for i := 1; i <= n; i++ {
+		s.SetBalance("XBT", bitx.Amount(i))
+	}
+
+	for _, test := range []struct {
+		minRow, maxRow int64
+		want           int
+	}{
+		{1, 0, n},
+		{1, n + 1, n},
+		{500, 1700, 1200},
+		{2400, 5000, 101},
+		{n + 1, 0, 0},
+	} {
+		it := c.Transactions(context.Background(), s.AccountID("XBT"),
+			test.minRow, test.maxRow)
+		row := test.minRow
+		for it.Next() {
+			if txn := it.Transaction(); txn.RowIndex != row ||
+				txn.Balance != bitx.Amount(row) {
+				t.Fatalf("Expected row %d, got %+v", row, txn)
+			}
+			row++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if got := int(row - test.minRow); got != test.want {
+			t.Errorf("Rows [%d, %d): expected %d transactions, got %d",
+				test.minRow, test.maxRow, test.want, got)
+		}
+	}
+}
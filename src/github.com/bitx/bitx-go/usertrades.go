@@ -0,0 +1,103 @@
+package bitx
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type userTrade struct {
+	Pair       string `json:"pair"`
+	Sequence   int64  `json:"sequence"`
+	OrderId    string `json:"order_id"`
+	Type       string `json:"type"`
+	Timestamp  int64  `json:"timestamp"`
+	Price      string `json:"price"`
+	Volume     string `json:"volume"`
+	Base       string `json:"base"`
+	Counter    string `json:"counter"`
+	FeeBase    string `json:"fee_base"`
+	FeeCounter string `json:"fee_counter"`
+	IsBuy      bool   `json:"is_buy"`
+	IsMaker    bool   `json:"is_maker"`
+}
+
+type userTrades struct {
+	apiResponse
+	Trades []userTrade `json:"trades"`
+}
+
+// UserTrade is an execution of one of the user's orders.
+type UserTrade struct {
+	Pair string
+	// Sequence orders the user's trades in a pair.
+	Sequence  int64
+	OrderId   string
+	Type      OrderType
+	Timestamp time.Time
+	// Price and Volume are the price and base volume of the execution.
+	Price, Volume       Amount
+	Base, Counter       Amount
+	FeeBase, FeeCounter Amount
+	// IsBuy is true if the user bought the base currency.
+	IsBuy bool
+	// IsMaker is true if the user's order was resting in the order book.
+	IsMaker bool
+	Raw     json.RawMessage // See WithRawJSON.
+}
+
+// Returns the user's trades in the given currency pair executed at or after
+// since, oldest first. A zero since lists the oldest trades. If limit is
+// zero, the server's default is used.
+func (c *Client) ListUserTrades(pair string, since time.Time, limit int) (
+	[]UserTrade, error) {
+	return c.ListUserTradesContext(context.Background(), pair, since, limit)
+}
+
+// ListUserTradesContext is like ListUserTrades but takes a context.
+func (c *Client) ListUserTradesContext(ctx context.Context, pair string,
+	since time.Time, limit int) ([]UserTrade, error) {
+	params := url.Values{"pair": {pair}}
+	if !since.IsZero() {
+		params.Set("since", strconv.FormatInt(since.UnixNano()/1e6, 10))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var r userTrades
+	err := c.call(ctx, "GET", "/api/1/listtrades", params, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.parser()
+	raws := r.rawList("trades")
+	tr := make([]UserTrade, len(r.Trades))
+	for i, t := range r.Trades {
+		field := index("trades", i)
+		tr[i] = UserTrade{
+			Pair:       t.Pair,
+			Sequence:   t.Sequence,
+			OrderId:    t.OrderId,
+			Type:       OrderType(t.Type),
+			Timestamp:  millis(t.Timestamp),
+			Price:      p.amount(field+".price", t.Price),
+			Volume:     p.amount(field+".volume", t.Volume),
+			Base:       p.amount(field+".base", t.Base),
+			Counter:    p.amount(field+".counter", t.Counter),
+			FeeBase:    p.amount(field+".fee_base", t.FeeBase),
+			FeeCounter: p.amount(field+".fee_counter", t.FeeCounter),
+			IsBuy:      t.IsBuy,
+			IsMaker:    t.IsMaker,
+			Raw:        rawAt(raws, i),
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return tr, nil
+}
@@ -0,0 +1,61 @@
+package bitx_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bitx/bitx-go"
+)
+
+func TestListUserTrades(t *testing.T) {
+	s, c := newFake(t, "ZAR", "10000")
+
+	bid, err := c.PostOrder("XBTZAR", bitx.BID, amount("1"), amount("5000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("0.5"), amount("5000"))
+	s.PlaceOrder("XBTZAR", bitx.ASK, amount("1"), amount("5500"))
+	buy, err := c.PostMarketOrder("XBTZAR", bitx.BUY, amount("1100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err := c.ListUserTrades("XBTZAR", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bitx.UserTrade{
+		{Pair: "XBTZAR", Sequence: 1, OrderId: bid, Type: bitx.BID,
+			Price: amount("5000"), Volume: amount("0.5"), Base: amount("0.5"),
+			Counter: amount("2500"), IsBuy: true, IsMaker: true},
+		{Pair: "XBTZAR", Sequence: 2, OrderId: buy, Type: bitx.BUY,
+			Price: amount("5500"), Volume: amount("0.2"), Base: amount("0.2"),
+			Counter: amount("1100"), IsBuy: true},
+	}
+	if len(trades) != len(want) {
+		t.Fatalf("Expected %d trades, got %+v", len(want), trades)
+	}
+	for i := range want {
+		want[i].Timestamp = trades[i].Timestamp
+		if !reflect.DeepEqual(trades[i], want[i]) {
+			t.Errorf("Expected %+v, got %+v", want[i], trades[i])
+		}
+	}
+
+	trades, err = c.ListUserTrades("XBTZAR", time.Time{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].OrderId != bid {
+		t.Errorf("Expected only the oldest trade, got %+v", trades)
+	}
+	trades, err = c.ListUserTrades("XBTZAR", time.Now().Add(time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 0 {
+		t.Errorf("Expected no trades in the future, got %+v", trades)
+	}
+}
@@ -4,20 +4,32 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 
+	"github.com/bitx/bitx-go"
 	"trading-bot/marketmaker"
 )
 
 var APIKey = flag.String("api_key", "", "API key")
 var APISecret = flag.String("api_secret", "", "API secret")
 var Pair = flag.String("currency_pair", "XBTZAR", "Currency to pair trade")
+var APIURL = flag.String("api_url", "", "Base URL of the API, if not the default")
 
 func main() {
 	flag.Parse()
 	fmt.Println("Welcome to the BitX trading bot playground!")
 
-	bot := marketmaker.NewBot(*APIKey, *APISecret, *Pair)
+	opts := []bitx.Option{bitx.WithRateLimit(1, 5)}
+	if *APIURL != "" {
+		u, err := url.Parse(*APIURL)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		opts = append(opts, bitx.WithBaseURL(u))
+	}
+
+	bot := marketmaker.NewBot(*APIKey, *APISecret, *Pair, opts...)
 	err := bot.Execute()
 
 	if err != nil {
@@ -3,39 +3,41 @@ package marketmaker
 import (
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/bitx/bitx-go"
 	"trading-bot/io"
 )
 
 const botName = "Sexy bot"
-const minVolume = 0.005
 
 type MarketMakerBot struct {
 	Name      string
 	apiKey    string
 	apiSecret string
 	pair      string
+	opts      []bitx.Option
 	client    *bitx.Client
+	market    *bitx.Market
 }
 
-func NewBot(apiKey, apiSecret, pair string) *MarketMakerBot {
+func NewBot(apiKey, apiSecret, pair string, opts ...bitx.Option) *MarketMakerBot {
 	return &MarketMakerBot{
 		Name:      botName,
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
 		pair:      pair,
+		opts:      opts,
 	}
 }
 
 type marketState struct {
-	bid       float64
-	ask       float64
+	bid       bitx.Amount
+	ask       bitx.Amount
+	tick      bitx.Amount
 	lastOrder *bitx.Order
 }
 
-func (state marketState) spread() float64 {
+func (state marketState) spread() bitx.Amount {
 	return state.ask - state.bid
 }
 
@@ -46,27 +48,39 @@ func (bot *MarketMakerBot) Execute() error {
 		return errors.New("Please supply API key and secret via command flags.")
 	}
 
-	bot.client = bitx.NewClient(bot.apiKey, bot.apiSecret)
+	bot.client = bitx.NewClient(bot.apiKey, bot.apiSecret, bot.opts...)
 	if bot.client == nil {
 		return errors.New(fmt.Sprintf("Expected valid BitX client, got: %v", bot.client))
 	}
 
-	// Check balance
-	bal, res, err := bot.client.Balance(strings.Replace(bot.pair, "XBT", "", 1))
+	var err error
+	bot.market, err = bot.client.Market(bot.pair)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error fetching balance: %s", err))
+		return errors.New(fmt.Sprintf("Error fetching market: %s", err))
 	}
-	log("Current balance: %f (Reserved: %f)\n", bal, res)
+	log("Market %s: volume %s to %s, price tick %s\n", bot.pair,
+		bot.market.MinVolume, bot.market.MaxVolume, bot.market.PriceTick())
 
-	if bal <= minVolume {
-		return errors.New("Insuficcient balance to place an order.")
+	// Check balances of both legs of the pair
+	base, counter := splitPair(bot.pair)
+	balances, err := bot.client.Balances(base, counter)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error fetching balances: %s", err))
+	}
+	for _, b := range balances {
+		log("Current %s balance: %s (Reserved: %s)\n", b.Asset, b.Balance, b.Reserved)
 	}
 
-	marketState, err := getMarketState(bot.client, nil, bot.pair)
+	marketState, err := getMarketState(bot.client, bot.market, nil)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Market not ripe: %s", err))
 	}
-	log("Current market\n\tspread: %f\n\tbid: %f\n\task: %f\n", marketState.spread(), marketState.bid, marketState.ask)
+	log("Current market\n\tspread: %s\n\tbid: %s\n\task: %s\n", marketState.spread(), marketState.bid, marketState.ask)
+
+	orderType, price := getNextOrderParams(marketState)
+	if !canPlaceOrder(balances, base, counter, orderType, price, bot.market.MinVolume) {
+		return errors.New("Insuficcient balance to place an order.")
+	}
 
 	doOrder, err := io.PromptYesNo("Place trade?")
 	if err != nil {
@@ -75,7 +89,7 @@ func (bot *MarketMakerBot) Execute() error {
 
 	var lastOrder *bitx.Order
 	for doOrder {
-		lastOrder, err = bot.placeNextOrder(marketState, minVolume)
+		lastOrder, err = bot.placeNextOrder(marketState, bot.market.MinVolume)
 		if err != nil {
 			return errors.New(fmt.Sprintf("Could not place next order: %s", err))
 		}
@@ -85,18 +99,40 @@ func (bot *MarketMakerBot) Execute() error {
 			return errors.New(fmt.Sprintf("Could not get user confirmation: %s", err))
 		}
 
-		marketState, err = getMarketState(bot.client, lastOrder, bot.pair)
+		marketState, err = getMarketState(bot.client, bot.market, lastOrder)
 		if err != nil {
 			return errors.New(fmt.Sprintf("Market not ripe: %s", err))
 		}
-		log("Current market\n\tspread: %f\n\tbid: %f\n\task: %f\n", marketState.spread(), marketState.bid, marketState.ask)
+		log("Current market\n\tspread: %s\n\tbid: %s\n\task: %s\n", marketState.spread(), marketState.bid, marketState.ask)
 	}
 
 	log("\n%s has finished working. Bye.\n")
 	return nil
 }
 
-func getMarketState(c *bitx.Client, lastOrder *bitx.Order, pair string) (state marketState, err error) {
+func splitPair(pair string) (base, counter string) {
+	return pair[:3], pair[3:]
+}
+
+// canPlaceOrder reports whether there is enough available balance in the leg
+// of the pair which an order of minVolume at price spends: the counter
+// currency for a BID and the base currency for an ASK.
+func canPlaceOrder(balances []bitx.Balance, base, counter string,
+	orderType bitx.OrderType, price, minVolume bitx.Amount) bool {
+	asset, need := base, minVolume
+	if orderType == bitx.BID {
+		asset, need = counter, minVolume.Mul(price)
+	}
+	for _, b := range balances {
+		if b.Asset == asset && b.Balance-b.Reserved >= need {
+			return true
+		}
+	}
+	return false
+}
+
+func getMarketState(c *bitx.Client, market *bitx.Market, lastOrder *bitx.Order) (state marketState, err error) {
+	pair := market.Pair
 	bids, asks, err := c.OrderBook(pair)
 	if err != nil {
 		return marketState{}, err
@@ -106,8 +142,9 @@ func getMarketState(c *bitx.Client, lastOrder *bitx.Order, pair string) (state m
 		return marketState{}, errors.New("Not enough liquidity on market.")
 	}
 	state = marketState{
-		bid: bids[0].Price,
-		ask: asks[0].Price,
+		bid:  bids[0].Price,
+		ask:  asks[0].Price,
+		tick: market.PriceTick(),
 	}
 
 	lastOrder, err = fetchOrRefreshLastOrder(c, lastOrder, pair)
@@ -127,6 +164,8 @@ func fetchOrRefreshLastOrder(c *bitx.Client, lastOrder *bitx.Order, pair string)
 			// First order in this run
 			return &orders[0], nil
 		}
+		// No orders placed yet
+		return nil, nil
 	}
 
 	// Refresh order
@@ -134,7 +173,7 @@ func fetchOrRefreshLastOrder(c *bitx.Client, lastOrder *bitx.Order, pair string)
 	return c.GetOrder(lastOrder.Id)
 }
 
-func (bot *MarketMakerBot) placeNextOrder(state marketState, volume float64) (order *bitx.Order, err error) {
+func (bot *MarketMakerBot) placeNextOrder(state marketState, volume bitx.Amount) (order *bitx.Order, err error) {
 	log("Last order: %+v\n", state.lastOrder)
 
 	// Check if last order has executed
@@ -150,21 +189,28 @@ func (bot *MarketMakerBot) placeNextOrder(state marketState, volume float64) (or
 
 func shouldPlaceNextOrder(state marketState) bool {
 	// Check if last order has executed
-	return state.lastOrder.State == bitx.Complete && state.spread() > 1
+	if state.lastOrder != nil && state.lastOrder.State != bitx.Complete {
+		return false
+	}
+	return state.spread() > state.tick
 }
 
-func getNextOrderParams(state marketState) (orderType bitx.OrderType, price float64) {
+func getNextOrderParams(state marketState) (orderType bitx.OrderType, price bitx.Amount) {
 	orderType = bitx.BID
-	price = state.bid + 1
+	price = state.bid + state.tick
 	if state.lastOrder != nil && state.lastOrder.Type == bitx.BID {
 		orderType = bitx.ASK
-		price = state.ask - 1
+		price = state.ask - state.tick
 	}
 	return orderType, price
 }
 
-func (bot *MarketMakerBot) placeOrder(orderType bitx.OrderType, price, volume float64) (*bitx.Order, error) {
-	log("Placing order of type: %s, price: %f, volume: %f\n", orderType, price, volume)
+func (bot *MarketMakerBot) placeOrder(orderType bitx.OrderType, price, volume bitx.Amount) (*bitx.Order, error) {
+	volume, price, err := bot.market.ValidateOrder(orderType, volume, price)
+	if err != nil {
+		return nil, err
+	}
+	log("Placing order of type: %s, price: %s, volume: %s\n", orderType, price, volume)
 	orderId, err := bot.client.PostOrder(bot.pair, orderType, volume, price)
 	if err != nil {
 		return nil, err
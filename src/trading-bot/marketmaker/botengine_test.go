@@ -2,13 +2,15 @@ package marketmaker
 
 import (
 	"github.com/bitx/bitx-go"
+	"github.com/bitx/bitx-go/bitxtest"
 	"testing"
 )
 
 func TestShouldPlaceNextOrderPending(t *testing.T) {
 	if shouldPlaceNextOrder(marketState{
-		bid:       100,
-		ask:       110,
+		bid:       100 * bitx.Unit,
+		ask:       110 * bitx.Unit,
+		tick:      bitx.Unit,
 		lastOrder: &bitx.Order{State: bitx.Pending},
 	}) {
 		t.Errorf("Expected not to place next order for Pending lastOrder and decent spread.")
@@ -17,16 +19,18 @@ func TestShouldPlaceNextOrderPending(t *testing.T) {
 
 func TestShouldPlaceNextOrderComplete(t *testing.T) {
 	if !shouldPlaceNextOrder(marketState{
-		bid:       100,
-		ask:       110,
+		bid:       100 * bitx.Unit,
+		ask:       110 * bitx.Unit,
+		tick:      bitx.Unit,
 		lastOrder: &bitx.Order{State: bitx.Complete},
 	}) {
 		t.Errorf("Expected to place next order for Complete lastOrder and decent spread.")
 	}
 
 	if shouldPlaceNextOrder(marketState{
-		bid:       100,
-		ask:       101,
+		bid:       100 * bitx.Unit,
+		ask:       101 * bitx.Unit,
+		tick:      bitx.Unit,
 		lastOrder: &bitx.Order{State: bitx.Complete},
 	}) {
 		t.Errorf("Expected to not place next order for Complete lastOrder and spread of 1.")
@@ -35,26 +39,137 @@ func TestShouldPlaceNextOrderComplete(t *testing.T) {
 
 func TestGetNextOrderParamsForAsk(t *testing.T) {
 	orderType, price := getNextOrderParams(marketState{
-		bid:       100,
+		bid:       100 * bitx.Unit,
+		tick:      bitx.Unit,
 		lastOrder: &bitx.Order{Type: bitx.ASK},
 	})
 	if orderType != bitx.BID {
 		t.Errorf("Expected OrderType of BID, got %s.", orderType)
 	}
-	if price != 101 {
-		t.Errorf("Expected price of 101, got %f.", price)
+	if price != 101*bitx.Unit {
+		t.Errorf("Expected price of 101, got %s.", price)
 	}
 }
 
 func TestGetNextOrderParamsForBid(t *testing.T) {
 	orderType, price := getNextOrderParams(marketState{
-		ask:       100,
+		ask:       100 * bitx.Unit,
+		tick:      bitx.Unit,
 		lastOrder: &bitx.Order{Type: bitx.BID},
 	})
 	if orderType != bitx.ASK {
 		t.Errorf("Expected OrderType of ASK, got %s.", orderType)
 	}
-	if price != 99 {
-		t.Errorf("Expected price of 99, got %f.", price)
+	if price != 99*bitx.Unit {
+		t.Errorf("Expected price of 99, got %s.", price)
+	}
+}
+
+func TestShouldPlaceFirstOrder(t *testing.T) {
+	if !shouldPlaceNextOrder(marketState{bid: 100 * bitx.Unit, ask: 110 * bitx.Unit, tick: bitx.Unit}) {
+		t.Errorf("Expected to place first order for decent spread.")
+	}
+}
+
+func TestCanPlaceOrder(t *testing.T) {
+	balances := []bitx.Balance{
+		{Asset: "XBT", Balance: bitx.Unit, Reserved: bitx.Unit},
+		{Asset: "ZAR", Balance: 100 * bitx.Unit},
+	}
+	minVolume := bitx.Unit / 1000
+	if !canPlaceOrder(balances, "XBT", "ZAR", bitx.BID, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected to place bid with available ZAR.")
+	}
+	if canPlaceOrder(balances, "XBT", "ZAR", bitx.BID, 200000*bitx.Unit, minVolume) {
+		t.Errorf("Expected not to place bid costing more than available ZAR.")
+	}
+	balances[1].Reserved = balances[1].Balance
+	if canPlaceOrder(balances, "XBT", "ZAR", bitx.BID, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected not to place order with all funds reserved.")
+	}
+}
+
+func TestCanPlaceOrderBaseOnly(t *testing.T) {
+	balances := []bitx.Balance{
+		{Asset: "XBT", Balance: bitx.Unit},
+		{Asset: "ZAR", Balance: 0},
+	}
+	minVolume := bitx.Unit / 1000
+	if canPlaceOrder(balances, "XBT", "ZAR", bitx.BID, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected not to place bid without ZAR.")
+	}
+	if !canPlaceOrder(balances, "XBT", "ZAR", bitx.ASK, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected to place ask with available XBT.")
+	}
+}
+
+func TestCanPlaceOrderCounterOnly(t *testing.T) {
+	balances := []bitx.Balance{
+		{Asset: "ZAR", Balance: 100 * bitx.Unit},
+	}
+	minVolume := bitx.Unit / 1000
+	if !canPlaceOrder(balances, "XBT", "ZAR", bitx.BID, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected to place bid with available ZAR.")
+	}
+	if canPlaceOrder(balances, "XBT", "ZAR", bitx.ASK, 5000*bitx.Unit, minVolume) {
+		t.Errorf("Expected not to place ask without XBT.")
+	}
+}
+
+func TestPlaceOrdersOnFakeExchange(t *testing.T) {
+	s := bitxtest.NewServer()
+	defer s.Close()
+	s.SetBalance("ZAR", 1000*bitx.Unit)
+	s.SetMarket(bitx.Market{
+		Pair:        "XBTZAR",
+		Status:      bitx.Active,
+		MinVolume:   bitx.Unit / 1000,
+		MaxVolume:   10 * bitx.Unit,
+		VolumeScale: 3,
+		MinPrice:    bitx.Unit,
+		MaxPrice:    100000 * bitx.Unit,
+		PriceScale:  1,
+	})
+	s.PlaceOrder("XBTZAR", bitx.BID, bitx.Unit, 5000*bitx.Unit)
+	s.PlaceOrder("XBTZAR", bitx.ASK, bitx.Unit, 5010*bitx.Unit)
+
+	bot := NewBot(bitxtest.KeyID, bitxtest.KeySecret, "XBTZAR")
+	bot.client = s.Client()
+	market, err := bot.client.Market(bot.pair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.market = market
+
+	state, err := getMarketState(bot.client, bot.market, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.bid != 5000*bitx.Unit || state.ask != 5010*bitx.Unit ||
+		state.tick != bitx.Unit/10 || state.lastOrder != nil {
+		t.Fatalf("Unexpected market state: %+v", state)
+	}
+
+	order, err := bot.placeNextOrder(state, market.MinVolume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Type != bitx.BID || order.LimitPrice != bitx.MustParseAmount("5000.1") ||
+		order.LimitVolume != market.MinVolume || order.State != bitx.Pending {
+		t.Errorf("Expected pending bid at 5000.1, got %+v", order)
+	}
+
+	// Someone sells into our bid, so the bot should switch to asking.
+	s.PlaceOrder("XBTZAR", bitx.ASK, market.MinVolume, order.LimitPrice)
+	state, err = getMarketState(bot.client, bot.market, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.lastOrder.State != bitx.Complete {
+		t.Errorf("Expected last order to be complete, got %+v", state.lastOrder)
+	}
+	if orderType, price := getNextOrderParams(state); orderType != bitx.ASK ||
+		price != bitx.MustParseAmount("5009.9") {
+		t.Errorf("Expected ask at 5009.9, got %s at %s", orderType, price)
 	}
 }